@@ -1,7 +1,70 @@
 package bridges
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 type Bridge interface {
 	Run(ctx context.Context)
 }
+
+type batchItemFailuresResponse struct {
+	BatchItemFailures []struct {
+		ItemIdentifier string `json:"itemIdentifier"`
+	} `json:"batchItemFailures"`
+}
+
+// batchItemFailures returns identifiers of the failed items reported by the batch response of the route,
+// e.g. events.SQSEventResponse or events.DynamoDBEventResponse.
+func batchItemFailures(resp interface{}) []string {
+	encoded, err := json.Marshal(resp)
+	response := batchItemFailuresResponse{}
+
+	if err != nil || json.Unmarshal(encoded, &response) != nil {
+		return nil
+	}
+
+	identifiers := []string{}
+
+	for _, failure := range response.BatchItemFailures {
+		identifiers = append(identifiers, failure.ItemIdentifier)
+	}
+
+	return identifiers
+}
+
+// firstFailedIndex returns the index of the earliest failed item, like the event source mapping of the streams
+// which retries from the lowest failed sequence number. Failures which do not identify an item of the batch
+// fail the whole batch, without failures all items are handled.
+func firstFailedIndex(identifiers []string, failures []string) int {
+	if len(failures) == 0 {
+		return len(identifiers)
+	}
+
+	failed := map[string]bool{}
+
+	for _, failure := range failures {
+		failed[failure] = true
+	}
+
+	first := -1
+
+	for i, identifier := range identifiers {
+		if !failed[identifier] {
+			continue
+		}
+
+		delete(failed, identifier)
+
+		if first < 0 {
+			first = i
+		}
+	}
+
+	if first < 0 || len(failed) > 0 {
+		return 0
+	}
+
+	return first
+}
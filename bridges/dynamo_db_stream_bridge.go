@@ -0,0 +1,437 @@
+package bridges
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams/dynamodbstreamsiface"
+)
+
+const (
+	dynamoDbStreamEventSource    = "aws:dynamodb"
+	dynamoDbStreamDefaultBatch   = 100
+	dynamoDbStreamDefaultPolling = time.Second
+	dynamoDbStreamHandleTimeout  = 30 * time.Second
+)
+
+// DynamoDbStreamBridge polls the DynamoDB stream and passes its records to the routing as Lambda events.
+// Shards are read parent first, the position in every shard is checkpointed in memory after the routing
+// handles the batch. Failed batches and the records from the first reported batch item failure are retried.
+// It's intended to be used for local development environments only, e.g. with LocalStack.
+type DynamoDbStreamBridge struct {
+	router           routing.Router
+	streamArn        string
+	targetArn        string
+	streams          dynamodbstreamsiface.DynamoDBStreamsAPI
+	awsRegion        string
+	logger           routing.Logger
+	startingPosition string
+	batchSize        int64
+	pollInterval     time.Duration
+
+	started     bool
+	openAtStart map[string]bool
+	checkpoints map[string]string
+	retries     map[string]string
+	iterators   map[string]string
+	closed      map[string]bool
+}
+
+// NewDynamoDbStreamBridge creates a bridge reading the stream from the LATEST position,
+// records are sent with the targetArn as the eventSourceARN, so the routes can match the deployed stream.
+func NewDynamoDbStreamBridge(
+	r routing.Router,
+	streamArn string,
+	targetArn string,
+	streams dynamodbstreamsiface.DynamoDBStreamsAPI,
+	awsRegion string,
+	logger routing.Logger,
+) *DynamoDbStreamBridge {
+	if logger == nil {
+		logger = &routing.NilLogger{}
+	}
+
+	return &DynamoDbStreamBridge{
+		router:           r,
+		streamArn:        streamArn,
+		targetArn:        targetArn,
+		streams:          streams,
+		awsRegion:        awsRegion,
+		logger:           logger,
+		startingPosition: dynamodbstreams.ShardIteratorTypeLatest,
+		batchSize:        dynamoDbStreamDefaultBatch,
+		pollInterval:     dynamoDbStreamDefaultPolling,
+		openAtStart:      map[string]bool{},
+		checkpoints:      map[string]string{},
+		retries:          map[string]string{},
+		iterators:        map[string]string{},
+		closed:           map[string]bool{},
+	}
+}
+
+// WithStartingPosition sets the position of the shards open at the start, TRIM_HORIZON or LATEST.
+// With LATEST the shards closed at the start are skipped, shards created later are always read from the TRIM_HORIZON.
+func (bridge *DynamoDbStreamBridge) WithStartingPosition(position string) *DynamoDbStreamBridge {
+	bridge.startingPosition = position
+
+	return bridge
+}
+
+// WithBatchSize limits the number of records in one event.
+func (bridge *DynamoDbStreamBridge) WithBatchSize(batchSize int64) *DynamoDbStreamBridge {
+	bridge.batchSize = batchSize
+
+	return bridge
+}
+
+// WithPollInterval sets the delay between the polls of the stream.
+func (bridge *DynamoDbStreamBridge) WithPollInterval(interval time.Duration) *DynamoDbStreamBridge {
+	bridge.pollInterval = interval
+
+	return bridge
+}
+
+// Run polls the stream until the context is done.
+func (bridge *DynamoDbStreamBridge) Run(ctx context.Context) {
+	go func(ctx context.Context) {
+		defer bridge.logger.Printf("Stopping DynamoDB Stream Bridge for: %s", bridge.streamArn)
+
+		for {
+			if err := bridge.poll(ctx); err != nil {
+				bridge.logger.Printf("Failed to consume stream records with error: %s", err.Error())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(bridge.pollInterval):
+			}
+		}
+	}(ctx)
+}
+
+// poll reads one batch from every open shard.
+func (bridge *DynamoDbStreamBridge) poll(ctx context.Context) error {
+	shards, err := bridge.describeShards(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+
+	for _, shard := range shards {
+		known[aws.StringValue(shard.ShardId)] = true
+	}
+
+	if !bridge.started {
+		bridge.start(shards)
+	}
+
+	for _, shard := range shards {
+		shardId := aws.StringValue(shard.ShardId)
+		parentId := aws.StringValue(shard.ParentShardId)
+
+		// children are read once their parent is read to the end, like Lambda keeps the order of the item changes
+		if bridge.closed[shardId] || (known[parentId] && !bridge.closed[parentId]) {
+			continue
+		}
+
+		if err := bridge.readShard(ctx, shardId); err != nil {
+			return err
+		}
+	}
+
+	bridge.started = true
+
+	return nil
+}
+
+// start remembers the shards open at the start, with LATEST the closed ones are skipped,
+// otherwise their children would be read from the TRIM_HORIZON and replay the stream.
+func (bridge *DynamoDbStreamBridge) start(shards []*dynamodbstreams.Shard) {
+	for _, shard := range shards {
+		shardId := aws.StringValue(shard.ShardId)
+
+		if shard.SequenceNumberRange == nil || shard.SequenceNumberRange.EndingSequenceNumber == nil {
+			bridge.openAtStart[shardId] = true
+		} else if bridge.startingPosition == dynamodbstreams.ShardIteratorTypeLatest {
+			bridge.closed[shardId] = true
+		}
+	}
+}
+
+func (bridge *DynamoDbStreamBridge) describeShards(ctx context.Context) ([]*dynamodbstreams.Shard, error) {
+	shards := []*dynamodbstreams.Shard{}
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(bridge.streamArn)}
+
+	for {
+		output, err := bridge.streams.DescribeStreamWithContext(ctx, input)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if output.StreamDescription == nil {
+			return shards, nil
+		}
+
+		shards = append(shards, output.StreamDescription.Shards...)
+
+		if output.StreamDescription.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+
+		input.ExclusiveStartShardId = output.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+func (bridge *DynamoDbStreamBridge) readShard(ctx context.Context, shardId string) error {
+	iterator, err := bridge.shardIterator(ctx, shardId)
+
+	if err != nil {
+		return err
+	}
+
+	output, err := bridge.streams.GetRecordsWithContext(ctx, &dynamodbstreams.GetRecordsInput{
+		ShardIterator: aws.String(iterator),
+		Limit:         aws.Int64(bridge.batchSize),
+	})
+
+	if err != nil {
+		// e.g. expired iterator, it's recreated from the checkpoint by the next poll
+		delete(bridge.iterators, shardId)
+
+		return err
+	}
+
+	if len(output.Records) > 0 {
+		if failed := bridge.handleRecords(ctx, shardId, output.Records); failed != "" {
+			bridge.retries[shardId] = failed
+			delete(bridge.iterators, shardId)
+
+			return nil
+		}
+
+		delete(bridge.retries, shardId)
+	}
+
+	if output.NextShardIterator == nil {
+		bridge.closed[shardId] = true
+		delete(bridge.iterators, shardId)
+
+		return nil
+	}
+
+	bridge.iterators[shardId] = aws.StringValue(output.NextShardIterator)
+
+	return nil
+}
+
+// shardIterator continues from the checkpoint, shards open at the start are read from the starting position,
+// the others from the TRIM_HORIZON.
+func (bridge *DynamoDbStreamBridge) shardIterator(ctx context.Context, shardId string) (string, error) {
+	if iterator, ok := bridge.iterators[shardId]; ok {
+		return iterator, nil
+	}
+
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(bridge.streamArn),
+		ShardId:           aws.String(shardId),
+		ShardIteratorType: aws.String(dynamodbstreams.ShardIteratorTypeTrimHorizon),
+	}
+
+	if retry, ok := bridge.retries[shardId]; ok {
+		input.ShardIteratorType = aws.String(dynamodbstreams.ShardIteratorTypeAtSequenceNumber)
+		input.SequenceNumber = aws.String(retry)
+	} else if checkpoint, ok := bridge.checkpoints[shardId]; ok {
+		input.ShardIteratorType = aws.String(dynamodbstreams.ShardIteratorTypeAfterSequenceNumber)
+		input.SequenceNumber = aws.String(checkpoint)
+	} else if bridge.openAtStart[shardId] {
+		input.ShardIteratorType = aws.String(bridge.startingPosition)
+	}
+
+	output, err := bridge.streams.GetShardIteratorWithContext(ctx, input)
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.ShardIterator), nil
+}
+
+// handleRecords passes the records to the routing and checkpoints the handled ones,
+// returns the sequence number of the first record to retry or an empty string.
+func (bridge *DynamoDbStreamBridge) handleRecords(
+	ctx context.Context,
+	shardId string,
+	streamRecords []*dynamodbstreams.Record,
+) string {
+	bridge.logger.Printf(
+		"Received %d records from the %s shard of the %s stream, passing them to the routing",
+		len(streamRecords),
+		shardId,
+		bridge.streamArn,
+	)
+
+	records := make([]interface{}, len(streamRecords))
+
+	for i, record := range streamRecords {
+		records[i] = bridge.record(record)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, dynamoDbStreamHandleTimeout)
+	defer cancel()
+
+	resp, err := bridge.router.Handle(reqCtx, map[string]interface{}{"Records": records})
+
+	if err != nil {
+		bridge.logger.Printf("Failed to handle stream records with error: %s, retrying", err.Error())
+
+		return dynamoDbStreamSequenceNumber(streamRecords[0])
+	}
+
+	sequenceNumbers := make([]string, len(streamRecords))
+
+	for i, record := range streamRecords {
+		sequenceNumbers[i] = dynamoDbStreamSequenceNumber(record)
+	}
+
+	handled := firstFailedIndex(sequenceNumbers, batchItemFailures(resp))
+
+	for _, sequenceNumber := range sequenceNumbers[:handled] {
+		bridge.checkpoints[shardId] = sequenceNumber
+	}
+
+	if handled < len(sequenceNumbers) {
+		bridge.logger.Printf("Stream record %s failed, retrying", sequenceNumbers[handled])
+
+		return sequenceNumbers[handled]
+	}
+
+	return ""
+}
+
+func dynamoDbStreamSequenceNumber(record *dynamodbstreams.Record) string {
+	if record.Dynamodb == nil {
+		return ""
+	}
+
+	return aws.StringValue(record.Dynamodb.SequenceNumber)
+}
+
+// record converts the stream record into the record of the Lambda event, see events.DynamoDBEventRecord.
+func (bridge *DynamoDbStreamBridge) record(record *dynamodbstreams.Record) map[string]interface{} {
+	change := map[string]interface{}{}
+
+	if streamRecord := record.Dynamodb; streamRecord != nil {
+		if streamRecord.ApproximateCreationDateTime != nil {
+			change["ApproximateCreationDateTime"] = streamRecord.ApproximateCreationDateTime.Unix()
+		}
+
+		if streamRecord.Keys != nil {
+			change["Keys"] = dynamoDbStreamImage(streamRecord.Keys)
+		}
+
+		if streamRecord.NewImage != nil {
+			change["NewImage"] = dynamoDbStreamImage(streamRecord.NewImage)
+		}
+
+		if streamRecord.OldImage != nil {
+			change["OldImage"] = dynamoDbStreamImage(streamRecord.OldImage)
+		}
+
+		change["SequenceNumber"] = aws.StringValue(streamRecord.SequenceNumber)
+		change["SizeBytes"] = aws.Int64Value(streamRecord.SizeBytes)
+		change["StreamViewType"] = aws.StringValue(streamRecord.StreamViewType)
+	}
+
+	eventSource := aws.StringValue(record.EventSource)
+
+	if eventSource == "" {
+		eventSource = dynamoDbStreamEventSource
+	}
+
+	awsRegion := aws.StringValue(record.AwsRegion)
+
+	if awsRegion == "" {
+		awsRegion = bridge.awsRegion
+	}
+
+	converted := map[string]interface{}{
+		"awsRegion":      awsRegion,
+		"dynamodb":       change,
+		"eventID":        aws.StringValue(record.EventID),
+		"eventName":      aws.StringValue(record.EventName),
+		"eventSource":    eventSource,
+		"eventVersion":   aws.StringValue(record.EventVersion),
+		"eventSourceARN": bridge.targetArn,
+	}
+
+	if record.UserIdentity != nil {
+		converted["userIdentity"] = map[string]interface{}{
+			"type":        aws.StringValue(record.UserIdentity.Type),
+			"principalId": aws.StringValue(record.UserIdentity.PrincipalId),
+		}
+	}
+
+	return converted
+}
+
+func dynamoDbStreamImage(image map[string]*dynamodb.AttributeValue) map[string]interface{} {
+	converted := map[string]interface{}{}
+
+	for name, value := range image {
+		converted[name] = dynamoDbStreamAttributeValue(value)
+	}
+
+	return converted
+}
+
+// dynamoDbStreamAttributeValue converts the attribute value into its JSON form used by Lambda,
+// binary values are base64 encoded.
+func dynamoDbStreamAttributeValue(value *dynamodb.AttributeValue) map[string]interface{} {
+	switch {
+	case value == nil:
+		return map[string]interface{}{"NULL": true}
+	case value.S != nil:
+		return map[string]interface{}{"S": aws.StringValue(value.S)}
+	case value.N != nil:
+		return map[string]interface{}{"N": aws.StringValue(value.N)}
+	case value.B != nil:
+		return map[string]interface{}{"B": base64.StdEncoding.EncodeToString(value.B)}
+	case value.BOOL != nil:
+		return map[string]interface{}{"BOOL": aws.BoolValue(value.BOOL)}
+	case value.NULL != nil:
+		return map[string]interface{}{"NULL": aws.BoolValue(value.NULL)}
+	case value.SS != nil:
+		return map[string]interface{}{"SS": aws.StringValueSlice(value.SS)}
+	case value.NS != nil:
+		return map[string]interface{}{"NS": aws.StringValueSlice(value.NS)}
+	case value.BS != nil:
+		encoded := make([]string, len(value.BS))
+
+		for i, binary := range value.BS {
+			encoded[i] = base64.StdEncoding.EncodeToString(binary)
+		}
+
+		return map[string]interface{}{"BS": encoded}
+	case value.L != nil:
+		list := make([]interface{}, len(value.L))
+
+		for i, item := range value.L {
+			list[i] = dynamoDbStreamAttributeValue(item)
+		}
+
+		return map[string]interface{}{"L": list}
+	case value.M != nil:
+		return map[string]interface{}{"M": dynamoDbStreamImage(value.M)}
+	}
+
+	return map[string]interface{}{"NULL": true}
+}
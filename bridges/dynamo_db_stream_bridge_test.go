@@ -0,0 +1,286 @@
+package bridges
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams/dynamodbstreamsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	dynamoDbStreamArn       = "arn:aws:dynamodb:eu-west-1:000000000000:table/users/stream/local"
+	dynamoDbStreamTargetArn = "arn:aws:dynamodb:eu-west-1:123456789012:table/users/stream/2020"
+)
+
+type streamsMock struct {
+	mock.Mock
+	dynamodbstreamsiface.DynamoDBStreamsAPI
+}
+
+func (m *streamsMock) DescribeStreamWithContext(
+	ctx aws.Context,
+	input *dynamodbstreams.DescribeStreamInput,
+	opts ...request.Option,
+) (*dynamodbstreams.DescribeStreamOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*dynamodbstreams.DescribeStreamOutput), args.Error(1)
+}
+
+func (m *streamsMock) GetShardIteratorWithContext(
+	ctx aws.Context,
+	input *dynamodbstreams.GetShardIteratorInput,
+	opts ...request.Option,
+) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*dynamodbstreams.GetShardIteratorOutput), args.Error(1)
+}
+
+func (m *streamsMock) GetRecordsWithContext(
+	ctx aws.Context,
+	input *dynamodbstreams.GetRecordsInput,
+	opts ...request.Option,
+) (*dynamodbstreams.GetRecordsOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*dynamodbstreams.GetRecordsOutput), args.Error(1)
+}
+
+func (m *streamsMock) onShards(shards ...*dynamodbstreams.Shard) {
+	m.
+		On("DescribeStreamWithContext", mock.Anything, &dynamodbstreams.DescribeStreamInput{
+			StreamArn: aws.String(dynamoDbStreamArn),
+		}).
+		Return(&dynamodbstreams.DescribeStreamOutput{
+			StreamDescription: &dynamodbstreams.StreamDescription{Shards: shards},
+		}, nil)
+}
+
+func (m *streamsMock) onIterator(shardId string, iteratorType string, sequenceNumber *string, iterator string) {
+	m.
+		On("GetShardIteratorWithContext", mock.Anything, &dynamodbstreams.GetShardIteratorInput{
+			StreamArn:         aws.String(dynamoDbStreamArn),
+			ShardId:           aws.String(shardId),
+			ShardIteratorType: aws.String(iteratorType),
+			SequenceNumber:    sequenceNumber,
+		}).
+		Once().
+		Return(&dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String(iterator)}, nil)
+}
+
+func (m *streamsMock) onRecords(iterator string, next *string, records ...*dynamodbstreams.Record) {
+	m.
+		On("GetRecordsWithContext", mock.Anything, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: aws.String(iterator),
+			Limit:         aws.Int64(dynamoDbStreamDefaultBatch),
+		}).
+		Once().
+		Return(&dynamodbstreams.GetRecordsOutput{NextShardIterator: next, Records: records}, nil)
+}
+
+func streamRecord(sequenceNumber string) *dynamodbstreams.Record {
+	return &dynamodbstreams.Record{
+		AwsRegion:    aws.String(awsRegionEuWest1),
+		EventID:      aws.String("event-" + sequenceNumber),
+		EventName:    aws.String("INSERT"),
+		EventSource:  aws.String("aws:dynamodb"),
+		EventVersion: aws.String("1.1"),
+		Dynamodb: &dynamodbstreams.StreamRecord{
+			ApproximateCreationDateTime: aws.Time(time.Unix(1600000000, 0)),
+			Keys: map[string]*dynamodb.AttributeValue{
+				"id": {S: aws.String("user-" + sequenceNumber)},
+			},
+			NewImage: map[string]*dynamodb.AttributeValue{
+				"id":     {S: aws.String("user-" + sequenceNumber)},
+				"age":    {N: aws.String("30")},
+				"active": {BOOL: aws.Bool(true)},
+				"avatar": {B: []byte{0x01, 0x02}},
+				"tags":   {SS: aws.StringSlice([]string{"a", "b"})},
+				"address": {M: map[string]*dynamodb.AttributeValue{
+					"city": {S: aws.String("Vilnius")},
+				}},
+				"scores": {L: []*dynamodb.AttributeValue{{N: aws.String("1")}, {NULL: aws.Bool(true)}}},
+			},
+			SequenceNumber: aws.String(sequenceNumber),
+			SizeBytes:      aws.Int64(42),
+			StreamViewType: aws.String("NEW_AND_OLD_IMAGES"),
+		},
+	}
+}
+
+func decodeDynamoDbEvent(t *testing.T, event map[string]interface{}) events.DynamoDBEvent {
+	decoded := events.DynamoDBEvent{}
+
+	encoded, err := json.Marshal(event)
+
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(encoded, &decoded))
+
+	return decoded
+}
+
+func Test_DynamoDbStreamBridge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Passes stream records to the routing as Lambda events", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(&dynamodbstreams.Shard{ShardId: aws.String("shard-1")})
+		streams.onIterator("shard-1", dynamodbstreams.ShardIteratorTypeTrimHorizon, nil, "iterator-1")
+		streams.onRecords("iterator-1", aws.String("iterator-2"), streamRecord("100"), streamRecord("101"))
+		streams.onRecords("iterator-2", aws.String("iterator-3"))
+
+		route := &eventRoute{}
+		bridge := NewDynamoDbStreamBridge(
+			routing.New().AddRoute(route),
+			dynamoDbStreamArn,
+			dynamoDbStreamTargetArn,
+			streams,
+			awsRegionEuWest1,
+			nil,
+		).WithStartingPosition(dynamodbstreams.ShardIteratorTypeTrimHorizon)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		streams.AssertExpectations(t)
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+
+		event := decodeDynamoDbEvent(t, route.events[0])
+		assert.Len(t, event.Records, 2)
+
+		record := event.Records[0]
+		assert.Equal(t, dynamoDbStreamTargetArn, record.EventSourceArn)
+		assert.Equal(t, "aws:dynamodb", record.EventSource)
+		assert.Equal(t, "INSERT", record.EventName)
+		assert.Equal(t, awsRegionEuWest1, record.AWSRegion)
+		assert.Equal(t, "100", record.Change.SequenceNumber)
+		assert.Equal(t, int64(42), record.Change.SizeBytes)
+		assert.Equal(t, time.Unix(1600000000, 0).UTC(), record.Change.ApproximateCreationDateTime.UTC())
+		assert.Equal(t, "user-100", record.Change.Keys["id"].String())
+		assert.Equal(t, "30", record.Change.NewImage["age"].Number())
+		assert.True(t, record.Change.NewImage["active"].Boolean())
+		assert.Equal(t, []byte{0x01, 0x02}, record.Change.NewImage["avatar"].Binary())
+		assert.Equal(t, []string{"a", "b"}, record.Change.NewImage["tags"].StringSet())
+		assert.Equal(t, "Vilnius", record.Change.NewImage["address"].Map()["city"].String())
+		assert.True(t, record.Change.NewImage["scores"].List()[1].IsNull())
+	})
+
+	t.Run("Retries records from the first batch item failure", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(&dynamodbstreams.Shard{ShardId: aws.String("shard-1")})
+		streams.onIterator("shard-1", dynamodbstreams.ShardIteratorTypeLatest, nil, "iterator-1")
+		streams.onRecords("iterator-1", aws.String("iterator-2"), streamRecord("100"), streamRecord("101"))
+		streams.onIterator("shard-1", dynamodbstreams.ShardIteratorTypeAtSequenceNumber, aws.String("101"), "iterator-retry")
+		streams.onRecords("iterator-retry", aws.String("iterator-3"), streamRecord("101"))
+
+		route := &eventRoute{response: events.DynamoDBEventResponse{
+			BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "101"}},
+		}}
+		bridge := NewDynamoDbStreamBridge(routing.New().AddRoute(route), dynamoDbStreamArn, dynamoDbStreamTargetArn, streams, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "100"}, bridge.checkpoints)
+
+		route.response = events.DynamoDBEventResponse{}
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+
+		streams.AssertExpectations(t)
+		assert.Len(t, route.events, 2)
+	})
+
+	t.Run("Retries records from the lowest failed sequence number", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(&dynamodbstreams.Shard{ShardId: aws.String("shard-1")})
+		streams.onIterator("shard-1", dynamodbstreams.ShardIteratorTypeLatest, nil, "iterator-1")
+		streams.onRecords("iterator-1", aws.String("iterator-2"), streamRecord("100"), streamRecord("101"), streamRecord("102"))
+
+		route := &eventRoute{response: events.DynamoDBEventResponse{
+			BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "102"}, {ItemIdentifier: "101"}},
+		}}
+		bridge := NewDynamoDbStreamBridge(routing.New().AddRoute(route), dynamoDbStreamArn, dynamoDbStreamTargetArn, streams, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "100"}, bridge.checkpoints)
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.retries)
+	})
+
+	t.Run("Retries the whole batch if a batch item failure is unknown", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(&dynamodbstreams.Shard{ShardId: aws.String("shard-1")})
+		streams.onIterator("shard-1", dynamodbstreams.ShardIteratorTypeLatest, nil, "iterator-1")
+		streams.onRecords("iterator-1", aws.String("iterator-2"), streamRecord("100"), streamRecord("101"))
+
+		route := &eventRoute{response: events.DynamoDBEventResponse{
+			BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "101"}, {ItemIdentifier: "999"}},
+		}}
+		bridge := NewDynamoDbStreamBridge(routing.New().AddRoute(route), dynamoDbStreamArn, dynamoDbStreamTargetArn, streams, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Empty(t, bridge.checkpoints)
+		assert.Equal(t, map[string]string{"shard-1": "100"}, bridge.retries)
+	})
+
+	t.Run("Skips shards closed at the start with the LATEST position", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(
+			&dynamodbstreams.Shard{
+				ShardId:             aws.String("parent"),
+				SequenceNumberRange: &dynamodbstreams.SequenceNumberRange{EndingSequenceNumber: aws.String("150")},
+			},
+			&dynamodbstreams.Shard{ShardId: aws.String("child"), ParentShardId: aws.String("parent")},
+		)
+		streams.onIterator("child", dynamodbstreams.ShardIteratorTypeLatest, nil, "child-1")
+		streams.onRecords("child-1", aws.String("child-2"), streamRecord("200"))
+
+		route := &eventRoute{}
+		bridge := NewDynamoDbStreamBridge(routing.New().AddRoute(route), dynamoDbStreamArn, dynamoDbStreamTargetArn, streams, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		streams.AssertExpectations(t)
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, "200", decodeDynamoDbEvent(t, route.events[0]).Records[0].Change.SequenceNumber)
+		assert.True(t, bridge.closed["parent"])
+	})
+
+	t.Run("Reads child shards after their parent is closed", func(t *testing.T) {
+		streams := &streamsMock{}
+		streams.onShards(
+			&dynamodbstreams.Shard{
+				ShardId:             aws.String("parent"),
+				SequenceNumberRange: &dynamodbstreams.SequenceNumberRange{EndingSequenceNumber: aws.String("150")},
+			},
+			&dynamodbstreams.Shard{ShardId: aws.String("child"), ParentShardId: aws.String("parent")},
+		)
+		streams.onIterator("parent", dynamodbstreams.ShardIteratorTypeTrimHorizon, nil, "parent-1")
+		streams.onRecords("parent-1", nil, streamRecord("100"))
+		streams.onIterator("child", dynamodbstreams.ShardIteratorTypeTrimHorizon, nil, "child-1")
+		streams.onRecords("child-1", aws.String("child-2"), streamRecord("200"))
+
+		route := &eventRoute{}
+		bridge := NewDynamoDbStreamBridge(routing.New().AddRoute(route), dynamoDbStreamArn, dynamoDbStreamTargetArn, streams, awsRegionEuWest1, nil).
+			WithStartingPosition(dynamodbstreams.ShardIteratorTypeTrimHorizon)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		streams.AssertExpectations(t)
+		assert.Len(t, route.events, 2)
+		assert.Equal(t, "100", decodeDynamoDbEvent(t, route.events[0]).Records[0].Change.SequenceNumber)
+		assert.Equal(t, "200", decodeDynamoDbEvent(t, route.events[1]).Records[0].Change.SequenceNumber)
+		assert.True(t, bridge.closed["parent"])
+	})
+}
@@ -0,0 +1,505 @@
+package bridges
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	HttpPayloadVersion1 = "1.0"
+	HttpPayloadVersion2 = "2.0"
+
+	HttpBridgeDefaultStage = "local"
+	HttpBridgeAnyMethod    = "ANY"
+
+	httpBridgeAccountId     = "123456789012"
+	httpBridgeApiId         = "offline"
+	httpBridgeTimeout       = 29 * time.Second
+	httpBridgeV1TimeFormat  = "02/Jan/2006:15:04:05 -0700"
+	httpBridgeV2DefaultKey  = "$default"
+	httpBridgeProxyResource = "/{proxy+}"
+	httpBridgeProxyParam    = "proxy"
+	httpBridgeCookieHeader  = "Cookie"
+	httpBridgeShutdownDelay = 5 * time.Second
+)
+
+// HttpBridge runs a local HTTP server which passes requests to the routing as API Gateway proxy events
+// and writes the proxy responses back, similar to serverless offline.
+// It's intended to be used for local development environments only.
+type HttpBridge struct {
+	router         routing.Router
+	addr           string
+	logger         routing.Logger
+	payloadVersion string
+	stage          string
+	resources      []httpBridgeResource
+}
+
+type httpBridgeResource struct {
+	method   string
+	template string
+	segments []string
+}
+
+// httpBridgeResponse covers API Gateway v1 and v2 proxy responses.
+type httpBridgeResponse struct {
+	StatusCode        *int                `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Cookies           []string            `json:"cookies"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// NewHttpBridge creates a bridge listening on the address, e.g. ":3000", which sends v1 proxy events.
+func NewHttpBridge(r routing.Router, addr string, logger routing.Logger) *HttpBridge {
+	if logger == nil {
+		logger = &routing.NilLogger{}
+	}
+
+	return &HttpBridge{
+		router:         r,
+		addr:           addr,
+		logger:         logger,
+		payloadVersion: HttpPayloadVersion1,
+		stage:          HttpBridgeDefaultStage,
+		resources:      []httpBridgeResource{},
+	}
+}
+
+// WithPayloadVersion sends API Gateway v1 (REST API) events handled by the routes.ApiGatewayRoute
+// or v2 (HTTP API) events handled by the routes.ApiGatewayV2Route.
+func (bridge *HttpBridge) WithPayloadVersion(version string) *HttpBridge {
+	bridge.payloadVersion = version
+
+	return bridge
+}
+
+// WithStage sets the stage of the request context, paths are served without the stage prefix.
+func (bridge *HttpBridge) WithStage(stage string) *HttpBridge {
+	bridge.stage = stage
+
+	return bridge
+}
+
+// WithResource declares the API Gateway resource, e.g. /users/{id} or /files/{path+}, to fill in
+// the resource, route key and path parameters. HttpBridgeAnyMethod matches all methods.
+// Requests which match no resource are sent as the /{proxy+} resource, or $default route in v2.
+func (bridge *HttpBridge) WithResource(method string, template string) *HttpBridge {
+	bridge.resources = append(bridge.resources, httpBridgeResource{
+		method:   method,
+		template: template,
+		segments: httpBridgeSegments(template),
+	})
+
+	return bridge
+}
+
+// Run starts the server, it's stopped when the context is done.
+func (bridge *HttpBridge) Run(ctx context.Context) {
+	server := &http.Server{Addr: bridge.addr, Handler: bridge}
+
+	go func() {
+		bridge.logger.Printf("Starting HTTP Bridge on: %s", bridge.addr)
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			bridge.logger.Printf("HTTP Bridge failed with error: %s", err.Error())
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		defer bridge.logger.Printf("Stopping HTTP Bridge on: %s", bridge.addr)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpBridgeShutdownDelay)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+}
+
+// ServeHTTP passes the request to the routing, so the bridge can be used with any HTTP server.
+func (bridge *HttpBridge) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, err := io.ReadAll(request.Body)
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	requestId := newRequestId()
+	event, err := bridge.event(request, body, requestId, time.Now())
+
+	if err != nil {
+		bridge.logger.Printf("Failed to create API Gateway event with error: %s", err.Error())
+		writeHttpBridgeMessage(writer, http.StatusInternalServerError, "Internal server error")
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), httpBridgeTimeout)
+	defer cancel()
+
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{AwsRequestID: requestId})
+	resp, err := bridge.router.Handle(ctx, event)
+
+	if err != nil {
+		if errorx.IsOfType(err, routing.RouterRouteNotFoundError) {
+			writeHttpBridgeMessage(writer, http.StatusNotFound, "Not Found")
+
+			return
+		}
+
+		bridge.logger.Printf("Request %s failed with error: %s", requestId, err.Error())
+		writeHttpBridgeMessage(writer, http.StatusBadGateway, "Internal server error")
+
+		return
+	}
+
+	bridge.writeResponse(writer, resp, requestId)
+}
+
+func (bridge *HttpBridge) event(
+	request *http.Request,
+	body []byte,
+	requestId string,
+	now time.Time,
+) (map[string]interface{}, error) {
+	var proxyEvent interface{}
+
+	if bridge.payloadVersion == HttpPayloadVersion2 {
+		proxyEvent = bridge.v2Event(request, body, requestId, now)
+	} else {
+		proxyEvent = bridge.v1Event(request, body, requestId, now)
+	}
+
+	encoded, err := json.Marshal(proxyEvent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	event := map[string]interface{}{}
+
+	return event, json.Unmarshal(encoded, &event)
+}
+
+func (bridge *HttpBridge) v1Event(
+	request *http.Request,
+	body []byte,
+	requestId string,
+	now time.Time,
+) events.APIGatewayProxyRequest {
+	path := request.URL.Path
+	resource, pathParameters, _ := bridge.resource(request.Method, path)
+	headers := httpBridgeHeaders(request)
+	query := request.URL.Query()
+	encodedBody, isBase64Encoded := httpBridgeBody(body)
+
+	event := events.APIGatewayProxyRequest{
+		Resource:          resource,
+		Path:              path,
+		HTTPMethod:        request.Method,
+		Headers:           map[string]string{},
+		MultiValueHeaders: headers,
+		PathParameters:    pathParameters,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:        httpBridgeAccountId,
+			ResourceID:       httpBridgeApiId,
+			Stage:            bridge.stage,
+			DomainName:       request.Host,
+			DomainPrefix:     strings.Split(request.Host, ".")[0],
+			RequestID:        requestId,
+			Protocol:         request.Proto,
+			Identity:         events.APIGatewayRequestIdentity{SourceIP: sourceIp(request), UserAgent: request.UserAgent()},
+			ResourcePath:     resource,
+			Path:             "/" + bridge.stage + path,
+			HTTPMethod:       request.Method,
+			RequestTime:      now.Format(httpBridgeV1TimeFormat),
+			RequestTimeEpoch: now.UnixNano() / int64(time.Millisecond),
+			APIID:            httpBridgeApiId,
+		},
+		Body:            encodedBody,
+		IsBase64Encoded: isBase64Encoded,
+	}
+
+	// the single value maps have the last value like in API Gateway
+	for name, values := range headers {
+		event.Headers[name] = values[len(values)-1]
+	}
+
+	if len(query) > 0 {
+		event.QueryStringParameters = map[string]string{}
+		event.MultiValueQueryStringParameters = map[string][]string(query)
+
+		for name, values := range query {
+			event.QueryStringParameters[name] = values[len(values)-1]
+		}
+	}
+
+	return event
+}
+
+func (bridge *HttpBridge) v2Event(
+	request *http.Request,
+	body []byte,
+	requestId string,
+	now time.Time,
+) events.APIGatewayV2HTTPRequest {
+	path := request.URL.Path
+	resource, pathParameters, declared := bridge.resource(request.Method, path)
+	routeKey := httpBridgeV2DefaultKey
+
+	if declared {
+		routeKey = request.Method + " " + resource
+	} else {
+		pathParameters = nil
+	}
+
+	encodedBody, isBase64Encoded := httpBridgeBody(body)
+
+	event := events.APIGatewayV2HTTPRequest{
+		Version:        HttpPayloadVersion2,
+		RouteKey:       routeKey,
+		RawPath:        path,
+		RawQueryString: request.URL.RawQuery,
+		Headers:        map[string]string{},
+		PathParameters: pathParameters,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:     routeKey,
+			AccountID:    httpBridgeAccountId,
+			Stage:        bridge.stage,
+			RequestID:    requestId,
+			APIID:        httpBridgeApiId,
+			DomainName:   request.Host,
+			DomainPrefix: strings.Split(request.Host, ".")[0],
+			Time:         now.Format(httpBridgeV1TimeFormat),
+			TimeEpoch:    now.UnixNano() / int64(time.Millisecond),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    request.Method,
+				Path:      path,
+				Protocol:  request.Proto,
+				SourceIP:  sourceIp(request),
+				UserAgent: request.UserAgent(),
+			},
+		},
+		Body:            encodedBody,
+		IsBase64Encoded: isBase64Encoded,
+	}
+
+	// v2 lower cases the headers, joins repeated values with commas and passes cookies separately
+	for name, values := range httpBridgeHeaders(request) {
+		if name == httpBridgeCookieHeader {
+			for _, value := range values {
+				event.Cookies = append(event.Cookies, strings.Split(value, "; ")...)
+			}
+
+			continue
+		}
+
+		event.Headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	if query := request.URL.Query(); len(query) > 0 {
+		event.QueryStringParameters = map[string]string{}
+
+		for name, values := range query {
+			event.QueryStringParameters[name] = strings.Join(values, ",")
+		}
+	}
+
+	return event
+}
+
+// resource finds the declared resource of the request, or falls back to the /{proxy+} resource.
+func (bridge *HttpBridge) resource(method string, path string) (string, map[string]string, bool) {
+	for _, resource := range bridge.resources {
+		if resource.method != HttpBridgeAnyMethod && resource.method != method {
+			continue
+		}
+
+		if parameters, ok := resource.match(path); ok {
+			return resource.template, parameters, true
+		}
+	}
+
+	if path == "/" {
+		return path, nil, false
+	}
+
+	return httpBridgeProxyResource, map[string]string{httpBridgeProxyParam: strings.TrimPrefix(path, "/")}, false
+}
+
+func (bridge *HttpBridge) writeResponse(writer http.ResponseWriter, resp interface{}, requestId string) {
+	encoded, err := json.Marshal(resp)
+	response := httpBridgeResponse{}
+
+	if err == nil {
+		err = json.Unmarshal(encoded, &response)
+	}
+
+	if bridge.payloadVersion == HttpPayloadVersion2 && (err != nil || response.StatusCode == nil) {
+		// HTTP API responds with the returned JSON when there is no status code
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(encoded)
+
+		return
+	}
+
+	if err != nil || response.StatusCode == nil || *response.StatusCode == 0 {
+		bridge.logger.Printf("Request %s returned malformed proxy response: %s", requestId, encoded)
+		writeHttpBridgeMessage(writer, http.StatusBadGateway, "Internal server error")
+
+		return
+	}
+
+	body := []byte(response.Body)
+
+	if response.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(response.Body); err != nil {
+			bridge.logger.Printf("Request %s returned invalid base64 body", requestId)
+			writeHttpBridgeMessage(writer, http.StatusBadGateway, "Internal server error")
+
+			return
+		}
+	}
+
+	for name, value := range response.Headers {
+		writer.Header().Set(name, value)
+	}
+
+	for name, values := range response.MultiValueHeaders {
+		writer.Header().Del(name)
+
+		for _, value := range values {
+			writer.Header().Add(name, value)
+		}
+	}
+
+	for _, cookie := range response.Cookies {
+		writer.Header().Add("Set-Cookie", cookie)
+	}
+
+	writer.WriteHeader(*response.StatusCode)
+	_, _ = writer.Write(body)
+}
+
+func (resource httpBridgeResource) match(path string) (map[string]string, bool) {
+	segments := httpBridgeSegments(path)
+	parameters := map[string]string{}
+
+	for i, expected := range resource.segments {
+		if strings.HasPrefix(expected, "{") && strings.HasSuffix(expected, "+}") {
+			if i >= len(segments) {
+				return nil, false
+			}
+
+			parameters[strings.TrimSuffix(expected[1:], "+}")] = strings.Join(segments[i:], "/")
+
+			return parameters, true
+		}
+
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if strings.HasPrefix(expected, "{") && strings.HasSuffix(expected, "}") {
+			parameters[expected[1:len(expected)-1]] = segments[i]
+
+			continue
+		}
+
+		if expected != segments[i] {
+			return nil, false
+		}
+	}
+
+	if len(segments) != len(resource.segments) {
+		return nil, false
+	}
+
+	if len(parameters) == 0 {
+		return nil, true
+	}
+
+	return parameters, true
+}
+
+func httpBridgeSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+
+	if trimmed == "" {
+		return []string{}
+	}
+
+	return strings.Split(trimmed, "/")
+}
+
+// httpBridgeHeaders returns headers of the request including the Host header removed by net/http.
+func httpBridgeHeaders(request *http.Request) map[string][]string {
+	headers := map[string][]string{}
+
+	for name, values := range request.Header {
+		headers[name] = append([]string{}, values...)
+	}
+
+	if request.Host != "" {
+		headers["Host"] = []string{request.Host}
+	}
+
+	return headers
+}
+
+// httpBridgeBody encodes bodies which are not valid UTF-8 with base64 like binary media types.
+func httpBridgeBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+func writeHttpBridgeMessage(writer http.ResponseWriter, statusCode int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_, _ = writer.Write(body)
+}
+
+func sourceIp(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
+
+// newRequestId returns a random UUID like the request IDs of API Gateway.
+func newRequestId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
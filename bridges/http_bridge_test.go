@@ -0,0 +1,223 @@
+package bridges
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRoute captures the raw events and responds with the given response.
+type eventRoute struct {
+	events   []map[string]interface{}
+	response interface{}
+}
+
+func (route *eventRoute) Matches(event map[string]interface{}) bool {
+	return true
+}
+
+func (route *eventRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	route.events = append(route.events, event)
+
+	return route.response, nil
+}
+
+func (route *eventRoute) HasResponse() bool {
+	return true
+}
+
+func Test_HttpBridge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sends API Gateway v1 events", func(t *testing.T) {
+		var received events.APIGatewayProxyRequest
+		var requestId string
+
+		route, err := routes.NewApiGatewayRoute(
+			"^/users/",
+			http.MethodPost,
+			func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				received = request
+				lambdaContext, _ := lambdacontext.FromContext(ctx)
+				requestId = lambdaContext.AwsRequestID
+
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusCreated, Body: "created"}, nil
+			},
+		)
+
+		assert.Nil(t, err)
+
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil).
+			WithStage("dev").
+			WithResource(http.MethodPost, "/users/{id}/files/{path+}")
+
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/users/1/files/a/b.txt?tag=a&tag=b", strings.NewReader("body"))
+		request.Header.Add("X-Tag", "a")
+		request.Header.Add("X-Tag", "b")
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "created", recorder.Body.String())
+
+		assert.Equal(t, "/users/{id}/files/{path+}", received.Resource)
+		assert.Equal(t, "/users/1/files/a/b.txt", received.Path)
+		assert.Equal(t, map[string]string{"id": "1", "path": "a/b.txt"}, received.PathParameters)
+		assert.Equal(t, map[string]string{"tag": "b"}, received.QueryStringParameters)
+		assert.Equal(t, map[string][]string{"tag": {"a", "b"}}, received.MultiValueQueryStringParameters)
+		assert.Equal(t, "b", received.Headers["X-Tag"])
+		assert.Equal(t, []string{"a", "b"}, received.MultiValueHeaders["X-Tag"])
+		assert.Equal(t, "localhost:3000", received.Headers["Host"])
+		assert.Equal(t, "body", received.Body)
+		assert.False(t, received.IsBase64Encoded)
+		assert.Equal(t, "dev", received.RequestContext.Stage)
+		assert.Equal(t, "/dev/users/1/files/a/b.txt", received.RequestContext.Path)
+		assert.Equal(t, "/users/{id}/files/{path+}", received.RequestContext.ResourcePath)
+		assert.Equal(t, "192.0.2.1", received.RequestContext.Identity.SourceIP)
+		assert.NotEmpty(t, received.RequestContext.RequestID)
+		assert.Equal(t, received.RequestContext.RequestID, requestId)
+	})
+
+	t.Run("Sends undeclared resources as proxy resource", func(t *testing.T) {
+		route := &eventRoute{response: events.APIGatewayProxyResponse{StatusCode: http.StatusOK}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil)
+
+		bridge.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, "/{proxy+}", route.events[0]["resource"])
+		assert.Equal(t, map[string]interface{}{"proxy": "orders/1"}, route.events[0]["pathParameters"])
+	})
+
+	t.Run("Encodes binary bodies with base64", func(t *testing.T) {
+		route := &eventRoute{response: events.APIGatewayProxyResponse{StatusCode: http.StatusOK}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil)
+
+		bridge.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodPut, "/files", strings.NewReader(string([]byte{0xff, 0x00}))),
+		)
+
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}), route.events[0]["body"])
+		assert.Equal(t, true, route.events[0]["isBase64Encoded"])
+	})
+
+	t.Run("Sends API Gateway v2 events", func(t *testing.T) {
+		route := &eventRoute{response: events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusOK,
+			Cookies:    []string{"a=1", "b=2"},
+		}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil).
+			WithPayloadVersion(HttpPayloadVersion2).
+			WithResource(HttpBridgeAnyMethod, "/users/{id}")
+
+		request := httptest.NewRequest(http.MethodGet, "/users/1?tag=a&tag=b", nil)
+		request.Header.Add("X-Tag", "a")
+		request.Header.Add("X-Tag", "b")
+		request.Header.Add("Cookie", "session=1; theme=dark")
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, []string{"a=1", "b=2"}, recorder.Header().Values("Set-Cookie"))
+
+		assert.Len(t, route.events, 1)
+		event := route.events[0]
+		requestContext := event["requestContext"].(map[string]interface{})
+
+		assert.Equal(t, "2.0", event["version"])
+		assert.Equal(t, "GET /users/{id}", event["routeKey"])
+		assert.Equal(t, "/users/1", event["rawPath"])
+		assert.Equal(t, "tag=a&tag=b", event["rawQueryString"])
+		assert.Equal(t, map[string]interface{}{"tag": "a,b"}, event["queryStringParameters"])
+		assert.Equal(t, map[string]interface{}{"id": "1"}, event["pathParameters"])
+		assert.Equal(t, []interface{}{"session=1", "theme=dark"}, event["cookies"])
+		assert.Equal(t, "a,b", event["headers"].(map[string]interface{})["x-tag"])
+		assert.Equal(t, "GET /users/{id}", requestContext["routeKey"])
+		assert.Equal(t, http.MethodGet, requestContext["http"].(map[string]interface{})["method"])
+	})
+
+	t.Run("Passes v2 events to the API Gateway v2 routes", func(t *testing.T) {
+		route, err := routes.NewApiGatewayV2Route(
+			"^\\/users\\/\\d+$",
+			http.MethodGet,
+			func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+				return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK, Body: request.PathParameters["id"]}, nil
+			},
+		)
+		assert.Nil(t, err)
+
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil).
+			WithPayloadVersion(HttpPayloadVersion2).
+			WithResource(http.MethodGet, "/users/{id}")
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "1", recorder.Body.String())
+	})
+
+	t.Run("Responds with JSON returned without status code in v2", func(t *testing.T) {
+		route := &eventRoute{response: map[string]interface{}{"id": 1}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil).WithPayloadVersion(HttpPayloadVersion2)
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, `{"id":1}`, recorder.Body.String())
+	})
+
+	t.Run("Writes headers and base64 bodies of the response", func(t *testing.T) {
+		route := &eventRoute{response: events.APIGatewayProxyResponse{
+			StatusCode:        http.StatusAccepted,
+			Headers:           map[string]string{"Content-Type": "image/png"},
+			MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+			Body:              base64.StdEncoding.EncodeToString([]byte{0x89, 0x50}),
+			IsBase64Encoded:   true,
+		}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil)
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+		assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, []string{"a=1", "b=2"}, recorder.Header().Values("Set-Cookie"))
+		assert.Equal(t, []byte{0x89, 0x50}, recorder.Body.Bytes())
+	})
+
+	t.Run("Responds with 502 if v1 response has no status code", func(t *testing.T) {
+		route := &eventRoute{response: events.APIGatewayProxyResponse{Body: "body"}}
+		bridge := NewHttpBridge(routing.New().AddRoute(route), ":0", nil)
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	})
+
+	t.Run("Responds with 404 if route is not found", func(t *testing.T) {
+		bridge := NewHttpBridge(routing.New(), ":0", nil)
+
+		recorder := httptest.NewRecorder()
+		bridge.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.JSONEq(t, `{"message":"Not Found"}`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,417 @@
+package bridges
+
+import (
+	"context"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+)
+
+const (
+	kinesisEventSource      = "aws:kinesis"
+	kinesisEventName        = "aws:kinesis:record"
+	kinesisEventVersion     = "1.0"
+	kinesisSchemaVersion    = "1.0"
+	kinesisDefaultBatch     = 100
+	kinesisDefaultPolling   = time.Second
+	kinesisHandleTimeout    = 30 * time.Second
+	kinesisUnlimitedRetries = -1
+	kinesisEventIdSeparator = ":"
+)
+
+// KinesisBridge polls all shards of the Kinesis stream and passes their records to the routing as Lambda events.
+// Failed batches are retried, optionally bisected, and batch item failures are retried from the first failed
+// record. Positions in the shards are checkpointed in memory.
+// It's intended to be used for local development environments only, e.g. with LocalStack.
+type KinesisBridge struct {
+	router            routing.Router
+	streamName        string
+	targetArn         string
+	kinesis           kinesisiface.KinesisAPI
+	awsRegion         string
+	logger            routing.Logger
+	startingPosition  string
+	startingTimestamp time.Time
+	batchSize         int64
+	pollInterval      time.Duration
+	bisect            bool
+	maxRetries        int
+
+	started     bool
+	startShards map[string]bool
+	checkpoints map[string]string
+	retries     map[string]string
+	attempts    map[string]int
+	iterators   map[string]string
+	closed      map[string]bool
+}
+
+// NewKinesisBridge creates a bridge reading the stream from the LATEST position,
+// records are sent with the targetArn as the eventSourceARN, so the routes can match the deployed stream.
+func NewKinesisBridge(
+	r routing.Router,
+	streamName string,
+	targetArn string,
+	kinesisClient kinesisiface.KinesisAPI,
+	awsRegion string,
+	logger routing.Logger,
+) *KinesisBridge {
+	if logger == nil {
+		logger = &routing.NilLogger{}
+	}
+
+	return &KinesisBridge{
+		router:           r,
+		streamName:       streamName,
+		targetArn:        targetArn,
+		kinesis:          kinesisClient,
+		awsRegion:        awsRegion,
+		logger:           logger,
+		startingPosition: kinesis.ShardIteratorTypeLatest,
+		batchSize:        kinesisDefaultBatch,
+		pollInterval:     kinesisDefaultPolling,
+		maxRetries:       kinesisUnlimitedRetries,
+		startShards:      map[string]bool{},
+		checkpoints:      map[string]string{},
+		retries:          map[string]string{},
+		attempts:         map[string]int{},
+		iterators:        map[string]string{},
+		closed:           map[string]bool{},
+	}
+}
+
+// WithStartingPosition sets the position of the shards open at the start, TRIM_HORIZON or LATEST.
+// With LATEST the shards closed at the start are skipped, shards created later are always read from the TRIM_HORIZON.
+func (bridge *KinesisBridge) WithStartingPosition(position string) *KinesisBridge {
+	bridge.startingPosition = position
+
+	return bridge
+}
+
+// WithStartingTimestamp reads the shards of the start from the records added at or after the timestamp.
+func (bridge *KinesisBridge) WithStartingTimestamp(timestamp time.Time) *KinesisBridge {
+	bridge.startingPosition = kinesis.ShardIteratorTypeAtTimestamp
+	bridge.startingTimestamp = timestamp
+
+	return bridge
+}
+
+// WithBatchSize limits the number of records in one event.
+func (bridge *KinesisBridge) WithBatchSize(batchSize int64) *KinesisBridge {
+	bridge.batchSize = batchSize
+
+	return bridge
+}
+
+// WithPollInterval sets the delay between the polls of the stream.
+func (bridge *KinesisBridge) WithPollInterval(interval time.Duration) *KinesisBridge {
+	bridge.pollInterval = interval
+
+	return bridge
+}
+
+// WithBisectBatchOnError splits batches failed with an error in two halves and retries them separately,
+// like BisectBatchOnFunctionError of the event source mapping.
+func (bridge *KinesisBridge) WithBisectBatchOnError() *KinesisBridge {
+	bridge.bisect = true
+
+	return bridge
+}
+
+// WithMaximumRetryAttempts skips the failed records after the number of retries, by default they are retried forever.
+func (bridge *KinesisBridge) WithMaximumRetryAttempts(attempts int) *KinesisBridge {
+	bridge.maxRetries = attempts
+
+	return bridge
+}
+
+// Run polls the stream until the context is done.
+func (bridge *KinesisBridge) Run(ctx context.Context) {
+	go func(ctx context.Context) {
+		defer bridge.logger.Printf("Stopping Kinesis Bridge for: %s", bridge.streamName)
+
+		for {
+			if err := bridge.poll(ctx); err != nil {
+				bridge.logger.Printf("Failed to consume Kinesis records with error: %s", err.Error())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(bridge.pollInterval):
+			}
+		}
+	}(ctx)
+}
+
+// poll reads one batch from every open shard, children are read once their parents are read to the end.
+func (bridge *KinesisBridge) poll(ctx context.Context) error {
+	shards, err := bridge.listShards(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+
+	for _, shard := range shards {
+		known[aws.StringValue(shard.ShardId)] = true
+	}
+
+	if !bridge.started {
+		bridge.start(shards)
+	}
+
+	for _, shard := range shards {
+		shardId := aws.StringValue(shard.ShardId)
+		parentIds := []string{aws.StringValue(shard.ParentShardId), aws.StringValue(shard.AdjacentParentShardId)}
+
+		if bridge.closed[shardId] || !bridge.parentsClosed(known, parentIds) {
+			continue
+		}
+
+		if err := bridge.readShard(ctx, shardId); err != nil {
+			return err
+		}
+	}
+
+	bridge.started = true
+
+	return nil
+}
+
+func (bridge *KinesisBridge) parentsClosed(known map[string]bool, parentIds []string) bool {
+	for _, parentId := range parentIds {
+		if known[parentId] && !bridge.closed[parentId] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// start remembers the shards read from the starting position, with LATEST the closed ones are skipped,
+// otherwise their children would be read from the TRIM_HORIZON and replay the stream.
+func (bridge *KinesisBridge) start(shards []*kinesis.Shard) {
+	for _, shard := range shards {
+		shardId := aws.StringValue(shard.ShardId)
+		closed := shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil
+
+		if closed && bridge.startingPosition == kinesis.ShardIteratorTypeLatest {
+			bridge.closed[shardId] = true
+
+			continue
+		}
+
+		bridge.startShards[shardId] = true
+	}
+}
+
+func (bridge *KinesisBridge) listShards(ctx context.Context) ([]*kinesis.Shard, error) {
+	shards := []*kinesis.Shard{}
+	input := &kinesis.ListShardsInput{StreamName: aws.String(bridge.streamName)}
+
+	for {
+		output, err := bridge.kinesis.ListShardsWithContext(ctx, input)
+
+		if err != nil {
+			return nil, err
+		}
+
+		shards = append(shards, output.Shards...)
+
+		if output.NextToken == nil {
+			return shards, nil
+		}
+
+		// the stream name must not be given together with the next token
+		input = &kinesis.ListShardsInput{NextToken: output.NextToken}
+	}
+}
+
+func (bridge *KinesisBridge) readShard(ctx context.Context, shardId string) error {
+	iterator, err := bridge.shardIterator(ctx, shardId)
+
+	if err != nil {
+		return err
+	}
+
+	output, err := bridge.kinesis.GetRecordsWithContext(ctx, &kinesis.GetRecordsInput{
+		ShardIterator: aws.String(iterator),
+		Limit:         aws.Int64(bridge.batchSize),
+	})
+
+	if err != nil {
+		// e.g. expired iterator, it's recreated from the checkpoint by the next poll
+		delete(bridge.iterators, shardId)
+
+		return err
+	}
+
+	if len(output.Records) > 0 && !bridge.processRecords(ctx, shardId, output.Records) {
+		delete(bridge.iterators, shardId)
+
+		return nil
+	}
+
+	if output.NextShardIterator == nil {
+		bridge.closed[shardId] = true
+		delete(bridge.iterators, shardId)
+
+		return nil
+	}
+
+	bridge.iterators[shardId] = aws.StringValue(output.NextShardIterator)
+
+	return nil
+}
+
+// shardIterator continues from the checkpoint, shards of the start are read from the starting position,
+// the others from the TRIM_HORIZON.
+func (bridge *KinesisBridge) shardIterator(ctx context.Context, shardId string) (string, error) {
+	if iterator, ok := bridge.iterators[shardId]; ok {
+		return iterator, nil
+	}
+
+	input := &kinesis.GetShardIteratorInput{
+		StreamName:        aws.String(bridge.streamName),
+		ShardId:           aws.String(shardId),
+		ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+	}
+
+	if retry, ok := bridge.retries[shardId]; ok {
+		input.ShardIteratorType = aws.String(kinesis.ShardIteratorTypeAtSequenceNumber)
+		input.StartingSequenceNumber = aws.String(retry)
+	} else if checkpoint, ok := bridge.checkpoints[shardId]; ok {
+		input.ShardIteratorType = aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber)
+		input.StartingSequenceNumber = aws.String(checkpoint)
+	} else if bridge.startShards[shardId] {
+		input.ShardIteratorType = aws.String(bridge.startingPosition)
+
+		if bridge.startingPosition == kinesis.ShardIteratorTypeAtTimestamp {
+			input.Timestamp = aws.Time(bridge.startingTimestamp)
+		}
+	}
+
+	output, err := bridge.kinesis.GetShardIteratorWithContext(ctx, input)
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.ShardIterator), nil
+}
+
+// processRecords handles the records and checkpoints the handled ones, returns false if some are retried.
+func (bridge *KinesisBridge) processRecords(ctx context.Context, shardId string, records []*kinesis.Record) bool {
+	bridge.logger.Printf(
+		"Received %d records from the %s shard of the %s stream, passing them to the routing",
+		len(records),
+		shardId,
+		bridge.streamName,
+	)
+
+	handled := bridge.handleRecords(ctx, shardId, records)
+
+	for _, record := range records[:handled] {
+		bridge.checkpoints[shardId] = aws.StringValue(record.SequenceNumber)
+	}
+
+	if handled == len(records) {
+		delete(bridge.retries, shardId)
+		delete(bridge.attempts, shardId)
+
+		return true
+	}
+
+	failed := aws.StringValue(records[handled].SequenceNumber)
+	bridge.attempts[shardId]++
+
+	if bridge.maxRetries != kinesisUnlimitedRetries && bridge.attempts[shardId] > bridge.maxRetries {
+		bridge.logger.Printf("Skipping %d Kinesis records from %s after %d retries", len(records)-handled, failed, bridge.maxRetries)
+
+		bridge.checkpoints[shardId] = aws.StringValue(records[len(records)-1].SequenceNumber)
+		delete(bridge.retries, shardId)
+		delete(bridge.attempts, shardId)
+
+		return true
+	}
+
+	bridge.logger.Printf("Kinesis record %s failed, retrying", failed)
+	bridge.retries[shardId] = failed
+
+	return false
+}
+
+// handleRecords passes the records to the routing and returns the number of the handled records from the start.
+// Errored batches are bisected when enabled, unknown batch item failures fail the whole batch like in Lambda.
+func (bridge *KinesisBridge) handleRecords(ctx context.Context, shardId string, records []*kinesis.Record) int {
+	events := make([]interface{}, len(records))
+
+	for i, record := range records {
+		events[i] = bridge.record(shardId, record)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, kinesisHandleTimeout)
+	defer cancel()
+
+	resp, err := bridge.router.Handle(reqCtx, map[string]interface{}{"Records": events})
+
+	if err != nil {
+		bridge.logger.Printf("Failed to handle %d Kinesis records with error: %s", len(records), err.Error())
+
+		if !bridge.bisect || len(records) == 1 {
+			return 0
+		}
+
+		half := len(records) / 2
+
+		if handled := bridge.handleRecords(ctx, shardId, records[:half]); handled < half {
+			return handled
+		}
+
+		return half + bridge.handleRecords(ctx, shardId, records[half:])
+	}
+
+	sequenceNumbers := make([]string, len(records))
+
+	for i, record := range records {
+		sequenceNumbers[i] = aws.StringValue(record.SequenceNumber)
+	}
+
+	return firstFailedIndex(sequenceNumbers, batchItemFailures(resp))
+}
+
+// record converts the Kinesis record into the record of the Lambda event, see events.KinesisEventRecord.
+// Data is base64 encoded by the JSON encoding of the bytes.
+func (bridge *KinesisBridge) record(shardId string, record *kinesis.Record) map[string]interface{} {
+	kinesisRecord := map[string]interface{}{
+		"data":                 record.Data,
+		"partitionKey":         aws.StringValue(record.PartitionKey),
+		"sequenceNumber":       aws.StringValue(record.SequenceNumber),
+		"kinesisSchemaVersion": kinesisSchemaVersion,
+	}
+
+	if record.ApproximateArrivalTimestamp != nil {
+		kinesisRecord["approximateArrivalTimestamp"] =
+			float64(record.ApproximateArrivalTimestamp.UnixNano()) / float64(time.Second)
+	}
+
+	if encryptionType := aws.StringValue(record.EncryptionType); encryptionType != "" {
+		kinesisRecord["encryptionType"] = encryptionType
+	}
+
+	return map[string]interface{}{
+		"awsRegion":         bridge.awsRegion,
+		"eventID":           shardId + kinesisEventIdSeparator + aws.StringValue(record.SequenceNumber),
+		"eventName":         kinesisEventName,
+		"eventSource":       kinesisEventSource,
+		"eventSourceARN":    bridge.targetArn,
+		"eventVersion":      kinesisEventVersion,
+		"invokeIdentityArn": "",
+		"kinesis":           kinesisRecord,
+	}
+}
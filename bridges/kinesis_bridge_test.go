@@ -0,0 +1,408 @@
+package bridges
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	kinesisStreamName = "orders"
+	kinesisTargetArn  = "arn:aws:kinesis:eu-west-1:123456789012:stream/orders"
+)
+
+type kinesisMock struct {
+	mock.Mock
+	kinesisiface.KinesisAPI
+}
+
+func (m *kinesisMock) ListShardsWithContext(
+	ctx aws.Context,
+	input *kinesis.ListShardsInput,
+	opts ...request.Option,
+) (*kinesis.ListShardsOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*kinesis.ListShardsOutput), args.Error(1)
+}
+
+func (m *kinesisMock) GetShardIteratorWithContext(
+	ctx aws.Context,
+	input *kinesis.GetShardIteratorInput,
+	opts ...request.Option,
+) (*kinesis.GetShardIteratorOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*kinesis.GetShardIteratorOutput), args.Error(1)
+}
+
+func (m *kinesisMock) GetRecordsWithContext(
+	ctx aws.Context,
+	input *kinesis.GetRecordsInput,
+	opts ...request.Option,
+) (*kinesis.GetRecordsOutput, error) {
+	args := m.Called(ctx, input)
+
+	return args.Get(0).(*kinesis.GetRecordsOutput), args.Error(1)
+}
+
+func (m *kinesisMock) onShards(shards ...*kinesis.Shard) {
+	m.
+		On("ListShardsWithContext", mock.Anything, &kinesis.ListShardsInput{StreamName: aws.String(kinesisStreamName)}).
+		Return(&kinesis.ListShardsOutput{Shards: shards}, nil)
+}
+
+func (m *kinesisMock) onIterator(input *kinesis.GetShardIteratorInput, iterator string) {
+	input.StreamName = aws.String(kinesisStreamName)
+
+	m.
+		On("GetShardIteratorWithContext", mock.Anything, input).
+		Once().
+		Return(&kinesis.GetShardIteratorOutput{ShardIterator: aws.String(iterator)}, nil)
+}
+
+func (m *kinesisMock) onRecords(iterator string, next *string, records ...*kinesis.Record) {
+	m.
+		On("GetRecordsWithContext", mock.Anything, &kinesis.GetRecordsInput{
+			ShardIterator: aws.String(iterator),
+			Limit:         aws.Int64(kinesisDefaultBatch),
+		}).
+		Once().
+		Return(&kinesis.GetRecordsOutput{NextShardIterator: next, Records: records}, nil)
+}
+
+func kinesisClosedRange(endingSequenceNumber string) *kinesis.SequenceNumberRange {
+	return &kinesis.SequenceNumberRange{
+		StartingSequenceNumber: aws.String("0"),
+		EndingSequenceNumber:   aws.String(endingSequenceNumber),
+	}
+}
+
+func kinesisRecord(sequenceNumber string) *kinesis.Record {
+	return &kinesis.Record{
+		ApproximateArrivalTimestamp: aws.Time(time.Unix(1600000000, 0)),
+		Data:                        []byte("order-" + sequenceNumber),
+		PartitionKey:                aws.String("partition-" + sequenceNumber),
+		SequenceNumber:              aws.String(sequenceNumber),
+	}
+}
+
+func decodeKinesisEvent(t *testing.T, event map[string]interface{}) events.KinesisEvent {
+	decoded := events.KinesisEvent{}
+
+	encoded, err := json.Marshal(event)
+
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(encoded, &decoded))
+
+	return decoded
+}
+
+func kinesisSequenceNumbers(t *testing.T, event map[string]interface{}) []string {
+	sequenceNumbers := []string{}
+
+	for _, record := range decodeKinesisEvent(t, event).Records {
+		sequenceNumbers = append(sequenceNumbers, record.Kinesis.SequenceNumber)
+	}
+
+	return sequenceNumbers
+}
+
+// failingKinesisRoute fails the batches containing the record with the given sequence number.
+type failingKinesisRoute struct {
+	eventRoute
+	t              *testing.T
+	sequenceNumber string
+}
+
+func (route *failingKinesisRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	route.events = append(route.events, event)
+
+	for _, sequenceNumber := range kinesisSequenceNumbers(route.t, event) {
+		if sequenceNumber == route.sequenceNumber {
+			return nil, errors.New("failed")
+		}
+	}
+
+	return nil, nil
+}
+
+func Test_KinesisBridge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Passes records to the routing as Lambda events", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+		}, "iterator-1")
+		client.onRecords("iterator-1", aws.String("iterator-2"), kinesisRecord("100"), kinesisRecord("101"))
+		client.onRecords("iterator-2", aws.String("iterator-3"))
+
+		route := &eventRoute{}
+		bridge := NewKinesisBridge(
+			routing.New().AddRoute(route),
+			kinesisStreamName,
+			kinesisTargetArn,
+			client,
+			awsRegionEuWest1,
+			nil,
+		).WithStartingPosition(kinesis.ShardIteratorTypeTrimHorizon)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+
+		event := decodeKinesisEvent(t, route.events[0])
+		assert.Len(t, event.Records, 2)
+
+		record := event.Records[0]
+		assert.Equal(t, kinesisTargetArn, record.EventSourceArn)
+		assert.Equal(t, "aws:kinesis", record.EventSource)
+		assert.Equal(t, "aws:kinesis:record", record.EventName)
+		assert.Equal(t, "shard-1:100", record.EventID)
+		assert.Equal(t, awsRegionEuWest1, record.AwsRegion)
+		assert.Equal(t, []byte("order-100"), record.Kinesis.Data)
+		assert.Equal(t, "partition-100", record.Kinesis.PartitionKey)
+		assert.Equal(t, "100", record.Kinesis.SequenceNumber)
+		assert.Equal(t, "1.0", record.Kinesis.KinesisSchemaVersion)
+		assert.Equal(t, time.Unix(1600000000, 0).UTC(), record.Kinesis.ApproximateArrivalTimestamp.UTC())
+	})
+
+	t.Run("Reads the shards from the starting timestamp", func(t *testing.T) {
+		timestamp := time.Unix(1600000000, 0)
+
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeAtTimestamp),
+			Timestamp:         aws.Time(timestamp),
+		}, "iterator-1")
+		client.onRecords("iterator-1", aws.String("iterator-2"))
+
+		bridge := NewKinesisBridge(routing.New(), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil).
+			WithStartingTimestamp(timestamp)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+	})
+
+	t.Run("Lists shards of all pages", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.
+			On("ListShardsWithContext", mock.Anything, &kinesis.ListShardsInput{StreamName: aws.String(kinesisStreamName)}).
+			Return(&kinesis.ListShardsOutput{
+				Shards:    []*kinesis.Shard{{ShardId: aws.String("shard-1")}},
+				NextToken: aws.String("token"),
+			}, nil)
+		client.
+			On("ListShardsWithContext", mock.Anything, &kinesis.ListShardsInput{NextToken: aws.String("token")}).
+			Return(&kinesis.ListShardsOutput{Shards: []*kinesis.Shard{{ShardId: aws.String("shard-2")}}}, nil)
+
+		bridge := NewKinesisBridge(routing.New(), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil)
+
+		shards, err := bridge.listShards(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, shards, 2)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("Retries records from the first batch item failure", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		}, "iterator-1")
+		client.onRecords("iterator-1", aws.String("iterator-2"), kinesisRecord("100"), kinesisRecord("101"))
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:                aws.String("shard-1"),
+			ShardIteratorType:      aws.String(kinesis.ShardIteratorTypeAtSequenceNumber),
+			StartingSequenceNumber: aws.String("101"),
+		}, "iterator-retry")
+		client.onRecords("iterator-retry", aws.String("iterator-3"), kinesisRecord("101"))
+
+		route := &eventRoute{response: events.KinesisEventResponse{
+			BatchItemFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "101"}},
+		}}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "100"}, bridge.checkpoints)
+
+		route.response = events.KinesisEventResponse{}
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+
+		client.AssertExpectations(t)
+		assert.Len(t, route.events, 2)
+	})
+
+	t.Run("Retries records from the lowest failed sequence number", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		}, "iterator-1")
+		client.onRecords("iterator-1", aws.String("iterator-2"), kinesisRecord("100"), kinesisRecord("101"), kinesisRecord("102"))
+
+		route := &eventRoute{response: events.KinesisEventResponse{
+			BatchItemFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "102"}, {ItemIdentifier: "101"}},
+		}}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "100"}, bridge.checkpoints)
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.retries)
+	})
+
+	t.Run("Bisects failed batches", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		}, "iterator-1")
+		client.onRecords(
+			"iterator-1",
+			aws.String("iterator-2"),
+			kinesisRecord("100"),
+			kinesisRecord("101"),
+			kinesisRecord("102"),
+			kinesisRecord("103"),
+		)
+
+		route := &failingKinesisRoute{t: t, sequenceNumber: "102"}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil).
+			WithBisectBatchOnError()
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+		assert.Equal(t, map[string]string{"shard-1": "102"}, bridge.retries)
+		assert.Equal(t, [][]string{
+			{"100", "101", "102", "103"},
+			{"100", "101"},
+			{"102", "103"},
+			{"102"},
+		}, [][]string{
+			kinesisSequenceNumbers(t, route.events[0]),
+			kinesisSequenceNumbers(t, route.events[1]),
+			kinesisSequenceNumbers(t, route.events[2]),
+			kinesisSequenceNumbers(t, route.events[3]),
+		})
+	})
+
+	t.Run("Skips failed records after the maximum retry attempts", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(&kinesis.Shard{ShardId: aws.String("shard-1")})
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("shard-1"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		}, "iterator-1")
+		client.onRecords("iterator-1", aws.String("iterator-2"), kinesisRecord("100"), kinesisRecord("101"))
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:                aws.String("shard-1"),
+			ShardIteratorType:      aws.String(kinesis.ShardIteratorTypeAtSequenceNumber),
+			StartingSequenceNumber: aws.String("100"),
+		}, "iterator-retry")
+		client.onRecords("iterator-retry", aws.String("iterator-3"), kinesisRecord("100"), kinesisRecord("101"))
+		client.onRecords("iterator-3", aws.String("iterator-4"))
+
+		route := &failingKinesisRoute{t: t, sequenceNumber: "100"}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil).
+			WithMaximumRetryAttempts(1)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Empty(t, bridge.checkpoints)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Equal(t, map[string]string{"shard-1": "101"}, bridge.checkpoints)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+		assert.Len(t, route.events, 2)
+	})
+
+	t.Run("Skips shards closed at the start with the LATEST position", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(
+			&kinesis.Shard{ShardId: aws.String("child"), ParentShardId: aws.String("parent"), AdjacentParentShardId: aws.String("adjacent")},
+			&kinesis.Shard{ShardId: aws.String("parent"), SequenceNumberRange: kinesisClosedRange("150")},
+			&kinesis.Shard{ShardId: aws.String("adjacent"), SequenceNumberRange: kinesisClosedRange("250")},
+		)
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("child"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		}, "child-1")
+		client.onRecords("child-1", aws.String("child-2"), kinesisRecord("300"))
+
+		route := &eventRoute{}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+		assert.Len(t, route.events, 1)
+		assert.Equal(t, []string{"300"}, kinesisSequenceNumbers(t, route.events[0]))
+	})
+
+	t.Run("Reads child shards after their parents are closed", func(t *testing.T) {
+		client := &kinesisMock{}
+		client.onShards(
+			&kinesis.Shard{ShardId: aws.String("child"), ParentShardId: aws.String("parent"), AdjacentParentShardId: aws.String("adjacent")},
+			&kinesis.Shard{ShardId: aws.String("parent"), SequenceNumberRange: kinesisClosedRange("150")},
+			&kinesis.Shard{ShardId: aws.String("adjacent"), SequenceNumberRange: kinesisClosedRange("250")},
+		)
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("parent"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+		}, "parent-1")
+		client.onRecords("parent-1", nil, kinesisRecord("100"))
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("adjacent"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+		}, "adjacent-1")
+		client.onRecords("adjacent-1", nil, kinesisRecord("200"))
+		client.onIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           aws.String("child"),
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon),
+		}, "child-1")
+		client.onRecords("child-1", aws.String("child-2"), kinesisRecord("300"))
+
+		route := &eventRoute{}
+		bridge := NewKinesisBridge(routing.New().AddRoute(route), kinesisStreamName, kinesisTargetArn, client, awsRegionEuWest1, nil).
+			WithStartingPosition(kinesis.ShardIteratorTypeTrimHorizon)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+		assert.Len(t, route.events, 2)
+
+		assert.Nil(t, bridge.poll(context.Background()))
+
+		client.AssertExpectations(t)
+		assert.Len(t, route.events, 3)
+		assert.Equal(t, []string{"300"}, kinesisSequenceNumbers(t, route.events[2]))
+	})
+}
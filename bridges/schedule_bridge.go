@@ -0,0 +1,208 @@
+package bridges
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	scheduleEventVersion    = "0"
+	scheduleEventSource     = "aws.events"
+	scheduleEventDetailType = "Scheduled Event"
+	scheduleHandleTimeout   = 30 * time.Second
+	scheduleRuleArnParts    = 6
+)
+
+// Clock is the source of the time of the ScheduleBridge, it can be replaced to test the schedules.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// ScheduleRule is the EventBridge (CloudWatch Events) rule triggered by the schedule expression.
+type ScheduleRule struct {
+	arn        string
+	region     string
+	accountId  string
+	expression ScheduleExpression
+}
+
+// NewScheduleRule creates the rule from its ARN, e.g. arn:aws:events:us-east-1:111111:rule/name,
+// and rate(...) or cron(...) expression, see ParseScheduleExpression.
+func NewScheduleRule(ruleArn string, expression string) (*ScheduleRule, error) {
+	arnParts := strings.SplitN(ruleArn, ":", scheduleRuleArnParts)
+
+	if len(arnParts) != scheduleRuleArnParts {
+		return nil, ScheduleRuleError.New("Invalid rule ARN: %s", ruleArn)
+	}
+
+	parsed, err := ParseScheduleExpression(expression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScheduleRule{
+		arn:        ruleArn,
+		region:     arnParts[3],
+		accountId:  arnParts[4],
+		expression: parsed,
+	}, nil
+}
+
+// Next returns the first time the rule is triggered after the given time.
+func (rule *ScheduleRule) Next(after time.Time) (time.Time, bool) {
+	return rule.expression.Next(after)
+}
+
+// ScheduleBridge passes "Scheduled Event" CloudWatch events of the rules to the routing at their scheduled times.
+// Missed triggers, e.g. while the machine was asleep, are sent once.
+// It's intended to be used for local development environments only.
+type ScheduleBridge struct {
+	router routing.Router
+	logger routing.Logger
+	clock  Clock
+	rules  []*ScheduleRule
+}
+
+// NewScheduleBridge creates a bridge using the system clock.
+func NewScheduleBridge(r routing.Router, logger routing.Logger) *ScheduleBridge {
+	if logger == nil {
+		logger = &routing.NilLogger{}
+	}
+
+	return &ScheduleBridge{
+		router: r,
+		logger: logger,
+		clock:  systemClock{},
+		rules:  []*ScheduleRule{},
+	}
+}
+
+// WithRule adds the rule, rates are counted from the start of the bridge.
+func (bridge *ScheduleBridge) WithRule(rule *ScheduleRule) *ScheduleBridge {
+	bridge.rules = append(bridge.rules, rule)
+
+	return bridge
+}
+
+// WithClock replaces the system clock.
+func (bridge *ScheduleBridge) WithClock(clock Clock) *ScheduleBridge {
+	bridge.clock = clock
+
+	return bridge
+}
+
+// Run triggers the rules until the context is done or none of them are triggered again.
+func (bridge *ScheduleBridge) Run(ctx context.Context) {
+	go func(ctx context.Context) {
+		defer bridge.logger.Printf("Stopping Schedule Bridge")
+
+		schedule := map[*ScheduleRule]time.Time{}
+		now := bridge.clock.Now()
+
+		for _, rule := range bridge.rules {
+			if next, ok := rule.Next(now); ok {
+				schedule[rule] = next
+			}
+		}
+
+		for len(schedule) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-bridge.clock.After(earliestScheduled(schedule).Sub(now)):
+			}
+
+			now = bridge.clock.Now()
+
+			for _, rule := range bridge.rules {
+				scheduled, ok := schedule[rule]
+
+				if !ok || scheduled.After(now) {
+					continue
+				}
+
+				bridge.trigger(ctx, rule, scheduled)
+
+				next, ok := rule.Next(scheduled)
+
+				if ok && !next.After(now) {
+					next, ok = rule.Next(now)
+				}
+
+				if ok {
+					schedule[rule] = next
+				} else {
+					delete(schedule, rule)
+				}
+			}
+		}
+	}(ctx)
+}
+
+func (bridge *ScheduleBridge) trigger(ctx context.Context, rule *ScheduleRule, scheduled time.Time) {
+	event, err := bridge.event(rule, scheduled)
+
+	if err != nil {
+		bridge.logger.Printf("Failed to create scheduled event for %s with error: %s", rule.arn, err.Error())
+
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, scheduleHandleTimeout)
+	defer cancel()
+
+	if _, err := bridge.router.Handle(reqCtx, event); err != nil {
+		bridge.logger.Printf("Scheduled event of %s failed with error: %s", rule.arn, err.Error())
+	}
+}
+
+func (bridge *ScheduleBridge) event(rule *ScheduleRule, scheduled time.Time) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(events.CloudWatchEvent{
+		Version:    scheduleEventVersion,
+		ID:         newRequestId(),
+		DetailType: scheduleEventDetailType,
+		Source:     scheduleEventSource,
+		AccountID:  rule.accountId,
+		Time:       scheduled.UTC(),
+		Region:     rule.region,
+		Resources:  []string{rule.arn},
+		Detail:     json.RawMessage("{}"),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	event := map[string]interface{}{}
+
+	return event, json.Unmarshal(encoded, &event)
+}
+
+func earliestScheduled(schedule map[*ScheduleRule]time.Time) time.Time {
+	earliest := time.Time{}
+
+	for _, scheduled := range schedule {
+		if earliest.IsZero() || scheduled.Before(earliest) {
+			earliest = scheduled
+		}
+	}
+
+	return earliest
+}
@@ -0,0 +1,126 @@
+package bridges
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hourlyRuleArn   = "arn:aws:events:eu-west-1:123456789012:rule/hourly"
+	halfHourRuleArn = "arn:aws:events:eu-west-1:123456789012:rule/half-hour"
+)
+
+// fakeClock fires the timers immediately by moving the time forward, lag delays the first timer.
+type fakeClock struct {
+	now time.Time
+	lag time.Duration
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) After(d time.Duration) <-chan time.Time {
+	clock.now = clock.now.Add(d + clock.lag)
+	clock.lag = 0
+
+	fired := make(chan time.Time, 1)
+	fired <- clock.now
+
+	return fired
+}
+
+func scheduledEventsRoute(t *testing.T, resources []string) (*routes.CloudwatchScheduledEventRoute, chan events.CloudWatchEvent) {
+	received := make(chan events.CloudWatchEvent)
+
+	route, err := routes.NewCloudwatchScheduledEventRoute(
+		resources,
+		func(ctx context.Context, request events.CloudWatchEvent) error {
+			select {
+			case received <- request:
+			case <-ctx.Done():
+			}
+
+			return nil
+		},
+	)
+
+	assert.Nil(t, err)
+
+	return route, received
+}
+
+func Test_ScheduleBridge(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.January, 1, 0, 10, 0, 0, time.UTC)
+
+	t.Run("Sends scheduled events of the rules at their times", func(t *testing.T) {
+		hourly, err := NewScheduleRule(hourlyRuleArn, "cron(0 * * * ? *)")
+		assert.Nil(t, err)
+
+		halfHour, err := NewScheduleRule(halfHourRuleArn, "rate(30 minutes)")
+		assert.Nil(t, err)
+
+		hourlyRoute, hourlyEvents := scheduledEventsRoute(t, []string{hourlyRuleArn})
+		halfHourRoute, halfHourEvents := scheduledEventsRoute(t, []string{halfHourRuleArn})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		NewScheduleBridge(routing.New().AddRoute(hourlyRoute).AddRoute(halfHourRoute), nil).
+			WithClock(&fakeClock{now: start}).
+			WithRule(hourly).
+			WithRule(halfHour).
+			Run(ctx)
+
+		event := <-halfHourEvents
+		assert.Equal(t, "Scheduled Event", event.DetailType)
+		assert.Equal(t, "aws.events", event.Source)
+		assert.Equal(t, "0", event.Version)
+		assert.Equal(t, "123456789012", event.AccountID)
+		assert.Equal(t, "eu-west-1", event.Region)
+		assert.Equal(t, []string{halfHourRuleArn}, event.Resources)
+		assert.Equal(t, start.Add(30*time.Minute), event.Time)
+		assert.NotEmpty(t, event.ID)
+		assert.JSONEq(t, "{}", string(event.Detail))
+
+		assert.Equal(t, time.Date(2024, time.January, 1, 1, 0, 0, 0, time.UTC), (<-hourlyEvents).Time)
+		assert.Equal(t, start.Add(60*time.Minute), (<-halfHourEvents).Time)
+		assert.Equal(t, start.Add(90*time.Minute), (<-halfHourEvents).Time)
+		assert.Equal(t, time.Date(2024, time.January, 1, 2, 0, 0, 0, time.UTC), (<-hourlyEvents).Time)
+	})
+
+	t.Run("Sends missed triggers once", func(t *testing.T) {
+		rule, err := NewScheduleRule(hourlyRuleArn, "rate(1 hour)")
+		assert.Nil(t, err)
+
+		route, received := scheduledEventsRoute(t, []string{hourlyRuleArn})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		NewScheduleBridge(routing.New().AddRoute(route), nil).
+			WithClock(&fakeClock{now: start, lag: 150 * time.Minute}).
+			WithRule(rule).
+			Run(ctx)
+
+		assert.Equal(t, start.Add(time.Hour), (<-received).Time)
+		assert.Equal(t, start.Add(4*time.Hour+30*time.Minute), (<-received).Time)
+	})
+
+	t.Run("Returns error on invalid rules", func(t *testing.T) {
+		_, err := NewScheduleRule("hourly", "rate(1 hour)")
+		assert.True(t, errorx.IsOfType(err, ScheduleRuleError))
+
+		_, err = NewScheduleRule(hourlyRuleArn, "cron(0 * * * * *)")
+		assert.True(t, errorx.IsOfType(err, ScheduleExpressionError))
+	})
+}
@@ -0,0 +1,450 @@
+package bridges
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joomcode/errorx"
+)
+
+var (
+	BridgeErrors = errorx.NewNamespace("bridge")
+
+	ScheduleExpressionError = BridgeErrors.NewType("schedule_expression")
+	ScheduleRuleError       = BridgeErrors.NewType("schedule_rule")
+)
+
+const (
+	cronAnyValue      = "*"
+	cronNoValue       = "?"
+	cronLast          = "L"
+	cronWeekday       = "W"
+	cronNth           = "#"
+	cronStep          = "/"
+	cronRange         = "-"
+	cronListSeparator = ","
+	cronMinYear       = 1970
+	cronMaxYear       = 2199
+	cronLastDayOfWeek = 7
+)
+
+var (
+	rateExpressionRegexp = regexp.MustCompile(`^rate\((\d+) (minutes?|hours?|days?)\)$`)
+	cronExpressionRegexp = regexp.MustCompile(`^cron\((.*)\)$`)
+
+	rateUnits = map[string]time.Duration{
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+	}
+
+	cronMinutes     = cronFieldSpec{name: "minutes", min: 0, max: 59}
+	cronHours       = cronFieldSpec{name: "hours", min: 0, max: 23}
+	cronDaysOfMonth = cronFieldSpec{name: "day-of-month", min: 1, max: 31}
+	cronMonths      = cronFieldSpec{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	cronDaysOfWeek = cronFieldSpec{name: "day-of-week", min: 1, max: 7, names: map[string]int{
+		"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+	}}
+	cronYears = cronFieldSpec{name: "year", min: cronMinYear, max: cronMaxYear}
+)
+
+// ScheduleExpression is the parsed rate or cron expression of the EventBridge (CloudWatch Events) rule.
+type ScheduleExpression interface {
+	// Next returns the first time the rule is triggered after the given time, false if it's never triggered again.
+	Next(after time.Time) (time.Time, bool)
+}
+
+// ParseScheduleExpression parses the expression in the AWS syntax, e.g. rate(5 minutes) or cron(0 12 ? * MON-FRI *).
+// Cron expressions are evaluated in UTC, one of the day-of-month and day-of-week fields must be "?".
+func ParseScheduleExpression(expression string) (ScheduleExpression, error) {
+	expression = strings.TrimSpace(expression)
+
+	if matches := rateExpressionRegexp.FindStringSubmatch(expression); matches != nil {
+		return parseRateExpression(matches[1], matches[2])
+	}
+
+	if matches := cronExpressionRegexp.FindStringSubmatch(expression); matches != nil {
+		return parseCronExpression(matches[1])
+	}
+
+	return nil, ScheduleExpressionError.New("Expected rate(...) or cron(...) expression, got: %s", expression)
+}
+
+type rateExpression struct {
+	interval time.Duration
+}
+
+func parseRateExpression(value string, unit string) (*rateExpression, error) {
+	count, err := strconv.Atoi(value)
+
+	if err != nil || count < 1 {
+		return nil, ScheduleExpressionError.New("Rate value must be a positive number, got: %s", value)
+	}
+
+	singular := strings.TrimSuffix(unit, "s")
+
+	if (count == 1) != (unit == singular) {
+		return nil, ScheduleExpressionError.New("Rate unit must be singular only for the value 1, got: %s %s", value, unit)
+	}
+
+	return &rateExpression{interval: time.Duration(count) * rateUnits[singular]}, nil
+}
+
+// Next counts the rate from the given time, e.g. the previous trigger or the start of the bridge.
+func (expression *rateExpression) Next(after time.Time) (time.Time, bool) {
+	return after.Add(expression.interval), true
+}
+
+type cronExpression struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth *cronDaysOfMonthField
+	months      []bool
+	daysOfWeek  *cronDaysOfWeekField
+	years       []bool
+}
+
+type cronFieldSpec struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+// cronDaysOfMonthField supports L (the last day), LW (the last weekday) and nW (the weekday nearest to n).
+type cronDaysOfMonthField struct {
+	days        []bool
+	last        bool
+	lastWeekday bool
+	weekdays    []int
+}
+
+// cronDaysOfWeekField supports L (Saturday), nL (the last n weekday) and n#k (the k-th n weekday of the month).
+type cronDaysOfWeekField struct {
+	days []bool
+	last []int
+	nth  [][2]int
+}
+
+func parseCronExpression(expression string) (*cronExpression, error) {
+	fields := strings.Fields(expression)
+
+	if len(fields) != 6 {
+		return nil, ScheduleExpressionError.New("Cron expression must have 6 fields, got: %s", expression)
+	}
+
+	if (fields[2] == cronNoValue) == (fields[4] == cronNoValue) {
+		return nil, ScheduleExpressionError.New(
+			"Exactly one of the day-of-month and day-of-week fields must be %s, got: %s",
+			cronNoValue,
+			expression,
+		)
+	}
+
+	cron := &cronExpression{}
+	var err error
+
+	if cron.minutes, err = cronMinutes.parse(fields[0]); err != nil {
+		return nil, err
+	}
+
+	if cron.hours, err = cronHours.parse(fields[1]); err != nil {
+		return nil, err
+	}
+
+	if fields[2] != cronNoValue {
+		if cron.daysOfMonth, err = parseCronDaysOfMonth(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	if cron.months, err = cronMonths.parse(fields[3]); err != nil {
+		return nil, err
+	}
+
+	if fields[4] != cronNoValue {
+		if cron.daysOfWeek, err = parseCronDaysOfWeek(fields[4]); err != nil {
+			return nil, err
+		}
+	}
+
+	if cron.years, err = cronYears.parse(fields[5]); err != nil {
+		return nil, err
+	}
+
+	return cron, nil
+}
+
+// Next returns the first matching minute after the given time.
+func (expression *cronExpression) Next(after time.Time) (time.Time, bool) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+
+	for t.Year() <= cronMaxYear {
+		year, month, day := t.Date()
+
+		switch {
+		case !expression.years[year]:
+			t = time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		case !expression.months[month]:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+		case !expression.matchesDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+		default:
+			if next, ok := expression.nextTimeOfDay(t); ok {
+				return next, true
+			}
+
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (expression *cronExpression) matchesDay(t time.Time) bool {
+	if expression.daysOfWeek != nil {
+		return expression.daysOfWeek.matches(t)
+	}
+
+	return expression.daysOfMonth.matches(t)
+}
+
+func (expression *cronExpression) nextTimeOfDay(t time.Time) (time.Time, bool) {
+	year, month, day := t.Date()
+
+	for hour := t.Hour(); hour < 24; hour++ {
+		if !expression.hours[hour] {
+			continue
+		}
+
+		minute := 0
+
+		if hour == t.Hour() {
+			minute = t.Minute()
+		}
+
+		for ; minute < 60; minute++ {
+			if expression.minutes[minute] {
+				return time.Date(year, month, day, hour, minute, 0, 0, time.UTC), true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parse parses comma separated values, ranges, * and steps, e.g. 0,30 or MON-FRI or */5 or 10-40/10.
+func (spec cronFieldSpec) parse(field string) ([]bool, error) {
+	values := make([]bool, spec.max+1)
+
+	for _, part := range strings.Split(field, cronListSeparator) {
+		if err := spec.parsePart(part, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (spec cronFieldSpec) parsePart(part string, values []bool) error {
+	rangePart, stepPart, hasStep := strings.Cut(part, cronStep)
+	step := 1
+
+	if hasStep {
+		var err error
+
+		if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+			return ScheduleExpressionError.New("Invalid step in the %s field: %s", spec.name, part)
+		}
+	}
+
+	from, to := spec.min, spec.max
+
+	if rangePart != cronAnyValue {
+		fromPart, toPart, isRange := strings.Cut(rangePart, cronRange)
+		var err error
+
+		if from, err = spec.value(fromPart); err != nil {
+			return err
+		}
+
+		if !isRange && !hasStep {
+			to = from
+		}
+
+		if isRange {
+			if to, err = spec.value(toPart); err != nil {
+				return err
+			}
+		}
+
+		if from > to {
+			return ScheduleExpressionError.New("Invalid range in the %s field: %s", spec.name, part)
+		}
+	}
+
+	for value := from; value <= to; value += step {
+		values[value] = true
+	}
+
+	return nil
+}
+
+func (spec cronFieldSpec) value(part string) (int, error) {
+	if value, ok := spec.names[strings.ToUpper(part)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(part)
+
+	if err != nil || value < spec.min || value > spec.max {
+		return 0, ScheduleExpressionError.New(
+			"Value of the %s field must be between %d and %d, got: %s",
+			spec.name,
+			spec.min,
+			spec.max,
+			part,
+		)
+	}
+
+	return value, nil
+}
+
+func parseCronDaysOfMonth(field string) (*cronDaysOfMonthField, error) {
+	daysOfMonth := &cronDaysOfMonthField{days: make([]bool, cronDaysOfMonth.max+1)}
+
+	for _, part := range strings.Split(field, cronListSeparator) {
+		switch {
+		case part == cronLast:
+			daysOfMonth.last = true
+		case part == cronLast+cronWeekday:
+			daysOfMonth.lastWeekday = true
+		case strings.HasSuffix(part, cronWeekday):
+			day, err := cronDaysOfMonth.value(strings.TrimSuffix(part, cronWeekday))
+
+			if err != nil {
+				return nil, err
+			}
+
+			daysOfMonth.weekdays = append(daysOfMonth.weekdays, day)
+		default:
+			if err := cronDaysOfMonth.parsePart(part, daysOfMonth.days); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return daysOfMonth, nil
+}
+
+func (field *cronDaysOfMonthField) matches(t time.Time) bool {
+	day := t.Day()
+	lastDay := daysInMonth(t)
+
+	if field.days[day] || (field.last && day == lastDay) {
+		return true
+	}
+
+	if field.lastWeekday && day == nearestWeekday(t, lastDay, lastDay) {
+		return true
+	}
+
+	for _, weekday := range field.weekdays {
+		if weekday <= lastDay && day == nearestWeekday(t, weekday, lastDay) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseCronDaysOfWeek(field string) (*cronDaysOfWeekField, error) {
+	daysOfWeek := &cronDaysOfWeekField{days: make([]bool, cronDaysOfWeek.max+1)}
+
+	for _, part := range strings.Split(field, cronListSeparator) {
+		switch {
+		case part == cronLast:
+			daysOfWeek.days[cronLastDayOfWeek] = true
+		case strings.HasSuffix(part, cronLast):
+			day, err := cronDaysOfWeek.value(strings.TrimSuffix(part, cronLast))
+
+			if err != nil {
+				return nil, err
+			}
+
+			daysOfWeek.last = append(daysOfWeek.last, day)
+		case strings.Contains(part, cronNth):
+			dayPart, nthPart, _ := strings.Cut(part, cronNth)
+			day, err := cronDaysOfWeek.value(dayPart)
+
+			if err != nil {
+				return nil, err
+			}
+
+			nth, err := strconv.Atoi(nthPart)
+
+			if err != nil || nth < 1 || nth > 5 {
+				return nil, ScheduleExpressionError.New("Invalid day-of-week occurrence: %s", part)
+			}
+
+			daysOfWeek.nth = append(daysOfWeek.nth, [2]int{day, nth})
+		default:
+			if err := cronDaysOfWeek.parsePart(part, daysOfWeek.days); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return daysOfWeek, nil
+}
+
+func (field *cronDaysOfWeekField) matches(t time.Time) bool {
+	weekday := int(t.Weekday()) + 1
+
+	if field.days[weekday] {
+		return true
+	}
+
+	for _, day := range field.last {
+		if day == weekday && t.Day()+7 > daysInMonth(t) {
+			return true
+		}
+	}
+
+	for _, nth := range field.nth {
+		if nth[0] == weekday && (t.Day()-1)/7+1 == nth[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the Monday to Friday day nearest to the day without leaving the month.
+func nearestWeekday(t time.Time, day int, lastDay int) int {
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+
+		return day + 1
+	}
+
+	return day
+}
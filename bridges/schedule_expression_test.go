@@ -0,0 +1,94 @@
+package bridges
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseScheduleExpression(t *testing.T) {
+	t.Parallel()
+
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		assert.Nil(t, err)
+
+		return parsed
+	}
+
+	t.Run("Returns next trigger times", func(t *testing.T) {
+		testCases := []struct {
+			expression string
+			after      string
+			next       string
+		}{
+			{"rate(1 minute)", "2024-01-01T00:00:30Z", "2024-01-01T00:01:30Z"},
+			{"rate(5 minutes)", "2024-01-01T00:00:00Z", "2024-01-01T00:05:00Z"},
+			{"rate(2 hours)", "2024-01-01T00:00:00Z", "2024-01-01T02:00:00Z"},
+			{"rate(1 day)", "2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z"},
+			{"cron(0 12 * * ? *)", "2024-01-01T00:00:00Z", "2024-01-01T12:00:00Z"},
+			{"cron(0/15 * * * ? *)", "2024-01-01T00:00:00Z", "2024-01-01T00:15:00Z"},
+			{"cron(10-40/10 * * * ? *)", "2024-01-01T00:25:00Z", "2024-01-01T00:30:00Z"},
+			{"cron(0 18 ? * MON-FRI *)", "2024-01-06T19:00:00Z", "2024-01-08T18:00:00Z"},
+			{"cron(0 8 1 * ? *)", "2024-01-01T09:00:00Z", "2024-02-01T08:00:00Z"},
+			{"cron(0 0 L * ? *)", "2024-02-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+			{"cron(0 0 LW * ? *)", "2024-03-01T00:00:00Z", "2024-03-29T00:00:00Z"},
+			{"cron(0 0 1W * ? *)", "2024-05-31T12:00:00Z", "2024-06-03T00:00:00Z"},
+			{"cron(0 0 15W * ? *)", "2024-09-01T00:00:00Z", "2024-09-16T00:00:00Z"},
+			{"cron(0 10 ? * 6L *)", "2024-01-01T00:00:00Z", "2024-01-26T10:00:00Z"},
+			{"cron(0 10 ? * 2#1 *)", "2024-01-02T00:00:00Z", "2024-02-05T10:00:00Z"},
+			{"cron(0 10 ? * L *)", "2024-01-01T00:00:00Z", "2024-01-06T10:00:00Z"},
+			{"cron(15 10 ? JUN,DEC SUN 2024)", "2024-01-01T00:00:00Z", "2024-06-02T10:15:00Z"},
+			{"cron(0 0 1 JAN ? 2025)", "2024-06-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		}
+
+		for _, testCase := range testCases {
+			expression, err := ParseScheduleExpression(testCase.expression)
+
+			assert.Nil(t, err, testCase.expression)
+
+			next, ok := expression.Next(date(testCase.after))
+
+			assert.True(t, ok, testCase.expression)
+			assert.Equal(t, date(testCase.next), next, testCase.expression)
+		}
+	})
+
+	t.Run("Returns false if the expression is never triggered again", func(t *testing.T) {
+		expression, err := ParseScheduleExpression("cron(0 0 1 JAN ? 2025)")
+
+		assert.Nil(t, err)
+
+		_, ok := expression.Next(date("2025-01-01T00:00:00Z"))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("Returns error on invalid expressions", func(t *testing.T) {
+		expressions := []string{
+			"every 5 minutes",
+			"rate(0 minutes)",
+			"rate(1 minutes)",
+			"rate(5 minute)",
+			"rate(5 weeks)",
+			"cron(* * * *)",
+			"cron(0 12 * * * *)",
+			"cron(0 12 ? * ? *)",
+			"cron(60 * * * ? *)",
+			"cron(5-1 * * * ? *)",
+			"cron(*/0 * * * ? *)",
+			"cron(0 0 ? * 2#6 *)",
+			"cron(0 0 ? FOO * *)",
+			"cron(0 0 32W * ? *)",
+		}
+
+		for _, expression := range expressions {
+			_, err := ParseScheduleExpression(expression)
+
+			assert.True(t, errorx.IsOfType(err, ScheduleExpressionError), expression)
+		}
+	})
+}
@@ -2,20 +2,53 @@ package bridges
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
 	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
-	"time"
 )
 
-type sqsBridge struct {
-	router    routing.Router
-	queueUrl  string
-	targetArn string
-	sqs       sqsiface.SQSAPI
-	awsRegion string
-	logger    routing.Logger
+const (
+	sqsDeleteBatchSize      = 10
+	sqsMaxReceiveBatchSize  = 10
+	sqsEventSource          = "aws:sqs"
+	sqsAllMessageAttributes = "All"
+	sqsDefaultBatchSize     = 10
+	sqsDefaultWaitTime      = 20 * time.Second
+	sqsMaxWaitTime          = 20 * time.Second
+	sqsDefaultConcurrency   = 1
+	sqsDefaultBackoff       = 100 * time.Millisecond
+	sqsDefaultMaxBackoff    = 30 * time.Second
+	sqsDefaultPolling       = time.Second
+)
+
+// SqsBridge fetches messages from SQS and passes them to the routing.
+// Handled messages are deleted, except the ones reported by the batch item failures response.
+// Polling mirrors the defaults of the event source mapping: long polling, 10 messages per batch and no batching window.
+// It's intended to be used for local development environments only.
+type SqsBridge struct {
+	router            routing.Router
+	queueUrl          string
+	targetArn         string
+	sqs               sqsiface.SQSAPI
+	awsRegion         string
+	logger            routing.Logger
+	deleteMessages    bool
+	batchSize         int
+	batchingWindow    time.Duration
+	waitTime          time.Duration
+	visibilityTimeout time.Duration
+	concurrency       int
+	backoff           time.Duration
+	maxBackoff        time.Duration
+	pollInterval      time.Duration
+	clock             Clock
 }
 
 func NewSqsBridge(
@@ -25,91 +58,412 @@ func NewSqsBridge(
 	sqs sqsiface.SQSAPI,
 	awsRegion string,
 	logger routing.Logger,
-) Bridge {
+) *SqsBridge {
 	if logger == nil {
 		logger = &routing.NilLogger{}
 	}
 
-	return &sqsBridge{
-		router:    r,
-		queueUrl:  queueUrl,
-		targetArn: targetArn,
-		sqs:       sqs,
-		awsRegion: awsRegion,
-		logger:    logger,
+	return &SqsBridge{
+		router:         r,
+		queueUrl:       queueUrl,
+		targetArn:      targetArn,
+		sqs:            sqs,
+		awsRegion:      awsRegion,
+		logger:         logger,
+		deleteMessages: true,
+		batchSize:      sqsDefaultBatchSize,
+		waitTime:       sqsDefaultWaitTime,
+		concurrency:    sqsDefaultConcurrency,
+		backoff:        sqsDefaultBackoff,
+		maxBackoff:     sqsDefaultMaxBackoff,
+		pollInterval:   sqsDefaultPolling,
+		clock:          systemClock{},
+	}
+}
+
+// WithDeleteHandledMessages enables or disables deleting of the handled messages, enabled by default.
+// Messages which are not deleted are received again after the visibility timeout.
+func (bridge *SqsBridge) WithDeleteHandledMessages(deleteMessages bool) *SqsBridge {
+	bridge.deleteMessages = deleteMessages
+
+	return bridge
+}
+
+// WithBatchSize limits the number of messages in one event, like BatchSize of the event source mapping.
+// More than 10 messages are gathered only within the batching window, sizes below 1 are ignored.
+func (bridge *SqsBridge) WithBatchSize(batchSize int) *SqsBridge {
+	if batchSize < 1 {
+		bridge.logger.Printf("Ignoring the SQS batch size %d, it must be at least 1", batchSize)
+
+		return bridge
+	}
+
+	bridge.batchSize = batchSize
+
+	return bridge
+}
+
+// WithBatchingWindow keeps receiving messages until the batch is full or the window passes,
+// like MaximumBatchingWindowInSeconds of the event source mapping.
+func (bridge *SqsBridge) WithBatchingWindow(window time.Duration) *SqsBridge {
+	bridge.batchingWindow = window
+
+	return bridge
+}
+
+// WithWaitTime sets the long polling wait time of the receive requests, up to 20 seconds, 0 for short polling.
+// Wait times out of the range are clamped to it.
+func (bridge *SqsBridge) WithWaitTime(waitTime time.Duration) *SqsBridge {
+	if waitTime < 0 || waitTime > sqsMaxWaitTime {
+		clamped := sqsMaxWaitTime
+
+		if waitTime < 0 {
+			clamped = 0
+		}
+
+		bridge.logger.Printf("SQS wait time %s is out of the 0 to %s range, using %s", waitTime, sqsMaxWaitTime, clamped)
+		waitTime = clamped
+	}
+
+	bridge.waitTime = waitTime
+
+	return bridge
+}
+
+// WithVisibilityTimeout overrides the visibility timeout of the queue for the received messages.
+func (bridge *SqsBridge) WithVisibilityTimeout(timeout time.Duration) *SqsBridge {
+	bridge.visibilityTimeout = timeout
+
+	return bridge
+}
+
+// WithMaximumConcurrency sets the number of workers receiving and handling batches in parallel,
+// like MaximumConcurrency of the event source mapping. Less than 1 worker is ignored.
+func (bridge *SqsBridge) WithMaximumConcurrency(workers int) *SqsBridge {
+	if workers < 1 {
+		bridge.logger.Printf("Ignoring the SQS maximum concurrency %d, it must be at least 1", workers)
+
+		return bridge
+	}
+
+	bridge.concurrency = workers
+
+	return bridge
+}
+
+// WithBackoff sets the delay after a failed receive, it's doubled on every following failure up to the maximum.
+// Delays which are not positive are ignored.
+func (bridge *SqsBridge) WithBackoff(initial time.Duration, maximum time.Duration) *SqsBridge {
+	if initial <= 0 || maximum <= 0 {
+		bridge.logger.Printf("Ignoring the SQS backoff from %s to %s, the delays must be positive", initial, maximum)
+
+		return bridge
 	}
+
+	bridge.backoff = initial
+	bridge.maxBackoff = maximum
+
+	return bridge
+}
+
+// WithPollInterval sets the delay after a receive without messages, so short polling does not call SQS in a loop.
+// Intervals which are not positive are ignored.
+func (bridge *SqsBridge) WithPollInterval(interval time.Duration) *SqsBridge {
+	if interval <= 0 {
+		bridge.logger.Printf("Ignoring the SQS poll interval %s, it must be positive", interval)
+
+		return bridge
+	}
+
+	bridge.pollInterval = interval
+
+	return bridge
+}
+
+// WithClock replaces the system clock used for the delays between the receives.
+func (bridge *SqsBridge) WithClock(clock Clock) *SqsBridge {
+	bridge.clock = clock
+
+	return bridge
 }
 
 // Run fetches messages from SQS and passes them to the routing.
 // It's intended to be used for local development environments only.
-func (bridge *sqsBridge) Run(ctx context.Context) {
-	go func(ctx context.Context) {
-		defer bridge.logger.Printf("Stopping SQS Bridge for: %s", bridge.queueUrl)
-
-	consumer:
-		for {
-			select {
-			case <-ctx.Done():
-				break consumer
-			default:
-				err := bridge.receiveMessage(ctx)
-
-				if err != nil {
-					bridge.logger.Printf("Failed to consume message with error: %s", err.Error())
-					// continue
-				}
+func (bridge *SqsBridge) Run(ctx context.Context) {
+	workers := sync.WaitGroup{}
+
+	for i := 0; i < bridge.concurrency; i++ {
+		workers.Add(1)
+
+		go func(ctx context.Context) {
+			defer workers.Done()
+
+			bridge.consume(ctx)
+		}(ctx)
+	}
+
+	go func() {
+		workers.Wait()
+		bridge.logger.Printf("Stopping SQS Bridge for: %s", bridge.queueUrl)
+	}()
+}
+
+func (bridge *SqsBridge) consume(ctx context.Context) {
+	backoff := bridge.backoff
+
+	for ctx.Err() == nil {
+		messages, err := bridge.receiveMessages(ctx)
+
+		if len(messages) > 0 {
+			if handleErr := bridge.handleMessages(ctx, messages); handleErr != nil {
+				bridge.logger.Printf("Failed to handle messages with error: %s", handleErr.Error())
 			}
 		}
-	}(ctx)
+
+		if err == nil {
+			backoff = bridge.backoff
+
+			if len(messages) == 0 {
+				bridge.wait(ctx, bridge.pollInterval)
+			}
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		bridge.logger.Printf("Failed to consume message with error: %s", err.Error())
+
+		bridge.wait(ctx, backoff)
+
+		if backoff *= 2; backoff > bridge.maxBackoff {
+			backoff = bridge.maxBackoff
+		}
+	}
 }
 
-func (bridge *sqsBridge) receiveMessage(ctx context.Context) error {
-	output, err := bridge.sqs.ReceiveMessageWithContext(
-		ctx,
-		&sqs.ReceiveMessageInput{
-			QueueUrl: aws.String(bridge.queueUrl),
-		},
-	)
+// wait blocks for the delay or until the context is done.
+func (bridge *SqsBridge) wait(ctx context.Context, delay time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-bridge.clock.After(delay):
+	}
+}
 
-	if err != nil {
-		return err
+// receiveMessages receives one batch, the batching window starts with the first received message
+// and within it the bridge keeps receiving until the batch is full. Messages received before an error are returned with it.
+func (bridge *SqsBridge) receiveMessages(ctx context.Context) ([]*sqs.Message, error) {
+	messages := []*sqs.Message{}
+	deadline := time.Time{}
+
+	for {
+		waitTime := bridge.waitTime
+
+		// the wait time is in whole seconds, so the rest of the window is rounded up instead of short polling
+		if remaining := deadline.Sub(bridge.clock.Now()); len(messages) > 0 && remaining < waitTime {
+			waitTime = (remaining + time.Second - 1) / time.Second * time.Second
+		}
+
+		output, err := bridge.sqs.ReceiveMessageWithContext(ctx, bridge.receiveInput(bridge.batchSize-len(messages), waitTime))
+
+		if err != nil {
+			return messages, err
+		}
+
+		if len(messages) == 0 {
+			deadline = bridge.clock.Now().Add(bridge.batchingWindow)
+		}
+
+		messages = append(messages, output.Messages...)
+
+		if len(messages) == 0 || len(messages) >= bridge.batchSize || !bridge.clock.Now().Before(deadline) {
+			return messages, nil
+		}
 	}
+}
 
-	messagesCount := len(output.Messages)
+func (bridge *SqsBridge) receiveInput(maxMessages int, waitTime time.Duration) *sqs.ReceiveMessageInput {
+	if maxMessages > sqsMaxReceiveBatchSize {
+		maxMessages = sqsMaxReceiveBatchSize
+	}
 
-	if messagesCount == 0 {
-		return nil
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(bridge.queueUrl),
+		AttributeNames:        aws.StringSlice([]string{sqs.QueueAttributeNameAll}),
+		MessageAttributeNames: aws.StringSlice([]string{sqsAllMessageAttributes}),
+		MaxNumberOfMessages:   aws.Int64(int64(maxMessages)),
+		WaitTimeSeconds:       aws.Int64(int64(waitTime / time.Second)),
 	}
 
+	if waitTime < 0 {
+		input.WaitTimeSeconds = aws.Int64(0)
+	}
+
+	if bridge.visibilityTimeout > 0 {
+		input.VisibilityTimeout = aws.Int64(int64(bridge.visibilityTimeout / time.Second))
+	}
+
+	return input
+}
+
+func (bridge *SqsBridge) handleMessages(ctx context.Context, messages []*sqs.Message) error {
 	bridge.logger.Printf(
 		"Received %d messages from the %s queue, passing them to the routing",
-		messagesCount,
+		len(messages),
 		bridge.queueUrl,
 	)
 
-	records := make([]interface{}, messagesCount)
+	event, err := bridge.event(messages)
+
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
 
-	for i, message := range output.Messages {
-		records[i] = map[string]interface{}{
-			"messageId":              message.MessageId,
-			"receiptHandle":          message.ReceiptHandle,
-			"body":                   message.Body,
-			"md5OfBody":              message.MD5OfBody,
-			"md5OfMessageAttributes": message.MD5OfMessageAttributes,
-			"attributes":             message.Attributes,
-			"messageAttributes":      message.MessageAttributes,
-			"eventSourceARN":         bridge.targetArn,
-			"eventSource":            bridge.queueUrl,
-			"awsRegion":              bridge.awsRegion,
+	resp, err := bridge.router.Handle(reqCtx, event)
+
+	if err != nil {
+		return err
+	}
+
+	if !bridge.deleteMessages {
+		return nil
+	}
+
+	return bridge.deleteHandledMessages(ctx, messages, batchItemFailures(resp))
+}
+
+// event converts the messages into the SQS event of Lambda, see events.SQSEvent.
+// Attributes include ApproximateReceiveCount and MessageGroupId, MessageDeduplicationId of FIFO queues.
+func (bridge *SqsBridge) event(messages []*sqs.Message) (map[string]interface{}, error) {
+	records := make([]interface{}, len(messages))
+
+	for i, message := range messages {
+		attributes := map[string]string{}
+
+		for name, value := range message.Attributes {
+			attributes[name] = aws.StringValue(value)
+		}
+
+		messageAttributes := map[string]events.SQSMessageAttribute{}
+
+		for name, value := range message.MessageAttributes {
+			messageAttributes[name] = events.SQSMessageAttribute{
+				StringValue:      value.StringValue,
+				BinaryValue:      value.BinaryValue,
+				StringListValues: append([]string{}, aws.StringValueSlice(value.StringListValues)...),
+				BinaryListValues: append([][]byte{}, value.BinaryListValues...),
+				DataType:         aws.StringValue(value.DataType),
+			}
 		}
+
+		record := map[string]interface{}{
+			"messageId":         aws.StringValue(message.MessageId),
+			"receiptHandle":     aws.StringValue(message.ReceiptHandle),
+			"body":              aws.StringValue(message.Body),
+			"md5OfBody":         aws.StringValue(message.MD5OfBody),
+			"attributes":        attributes,
+			"messageAttributes": messageAttributes,
+			"eventSourceARN":    bridge.targetArn,
+			"eventSource":       sqsEventSource,
+			"awsRegion":         bridge.awsRegion,
+		}
+
+		if message.MD5OfMessageAttributes != nil {
+			record["md5OfMessageAttributes"] = aws.StringValue(message.MD5OfMessageAttributes)
+		}
+
+		records[i] = record
 	}
 
-	event := map[string]interface{}{
-		"Records": records,
+	encoded, err := json.Marshal(map[string]interface{}{"Records": records})
+
+	if err != nil {
+		return nil, err
 	}
 
-	reqCtx, _ := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
-	_, err = bridge.router.Handle(reqCtx, event)
+	event := map[string]interface{}{}
+
+	return event, json.Unmarshal(encoded, &event)
+}
+
+// deleteHandledMessages deletes all messages except the failed ones, like the Lambda event source mapping
+// it keeps the whole batch if a failure does not identify a message of the batch.
+func (bridge *SqsBridge) deleteHandledMessages(ctx context.Context, messages []*sqs.Message, failures []string) error {
+	failed := map[string]bool{}
+
+	for _, failure := range failures {
+		failed[failure] = true
+	}
+
+	handled := []*sqs.Message{}
+
+	for _, message := range messages {
+		if failed[aws.StringValue(message.MessageId)] {
+			delete(failed, aws.StringValue(message.MessageId))
+
+			continue
+		}
+
+		handled = append(handled, message)
+	}
+
+	if len(failed) > 0 {
+		bridge.logger.Printf("Batch item failures do not match the received messages, keeping all of them")
+
+		return nil
+	}
+
+	for start := 0; start < len(handled); start += sqsDeleteBatchSize {
+		end := start + sqsDeleteBatchSize
+
+		if end > len(handled) {
+			end = len(handled)
+		}
+
+		if err := bridge.deleteMessageBatch(ctx, handled[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bridge *SqsBridge) deleteMessageBatch(ctx context.Context, messages []*sqs.Message) error {
+	entries := make([]*sqs.DeleteMessageBatchRequestEntry, len(messages))
+
+	for i, message := range messages {
+		entries[i] = &sqs.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: message.ReceiptHandle,
+		}
+	}
+
+	output, err := bridge.sqs.DeleteMessageBatchWithContext(
+		ctx,
+		&sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(bridge.queueUrl),
+			Entries:  entries,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, failure := range output.Failed {
+		index, _ := strconv.Atoi(aws.StringValue(failure.Id))
+
+		bridge.logger.Printf(
+			"Failed to delete message %s with error: %s",
+			aws.StringValue(messages[index].MessageId),
+			aws.StringValue(failure.Message),
+		)
+	}
 
-	return err
+	return nil
 }
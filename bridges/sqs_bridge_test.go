@@ -2,15 +2,22 @@ package bridges
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	routing "github.com/Napas/go-serverless-router"
 	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -41,7 +48,11 @@ func Test_sqsBridge(t *testing.T) {
 			On("ReceiveMessageWithContext",
 				mock.Anything,
 				&sqs.ReceiveMessageInput{
-					QueueUrl: aws.String(sqsQueueUrl),
+					QueueUrl:              aws.String(sqsQueueUrl),
+					AttributeNames:        aws.StringSlice([]string{"All"}),
+					MessageAttributeNames: aws.StringSlice([]string{"All"}),
+					MaxNumberOfMessages:   aws.Int64(10),
+					WaitTimeSeconds:       aws.Int64(20),
 				},
 				mock.Anything,
 			).
@@ -61,6 +72,7 @@ func Test_sqsBridge(t *testing.T) {
 							MessageAttributes: map[string]*sqs.MessageAttributeValue{
 								"attribute": {
 									StringValue: aws.String("value"),
+									DataType:    aws.String("String"),
 								},
 							},
 						},
@@ -88,21 +100,24 @@ func Test_sqsBridge(t *testing.T) {
 				map[string]interface{}{
 					"Records": []interface{}{
 						map[string]interface{}{
-							"messageId":              aws.String("messageId"),
-							"receiptHandle":          aws.String("receiptHandle"),
-							"body":                   aws.String("body"),
-							"md5OfBody":              aws.String("md5OfBody"),
-							"md5OfMessageAttributes": aws.String("md5OfMessageAttributes"),
-							"attributes": map[string]*string{
-								"attribute": aws.String("value"),
+							"messageId":              "messageId",
+							"receiptHandle":          "receiptHandle",
+							"body":                   "body",
+							"md5OfBody":              "md5OfBody",
+							"md5OfMessageAttributes": "md5OfMessageAttributes",
+							"attributes": map[string]interface{}{
+								"attribute": "value",
 							},
-							"messageAttributes": map[string]*sqs.MessageAttributeValue{
-								"attribute": {
-									StringValue: aws.String("value"),
+							"messageAttributes": map[string]interface{}{
+								"attribute": map[string]interface{}{
+									"stringValue":      "value",
+									"stringListValues": []interface{}{},
+									"binaryListValues": []interface{}{},
+									"dataType":         "String",
 								},
 							},
 							"eventSourceARN": sqsTargetArn,
-							"eventSource":    sqsQueueUrl,
+							"eventSource":    "aws:sqs",
 							"awsRegion":      awsRegionEuWest1,
 						},
 					},
@@ -110,6 +125,13 @@ func Test_sqsBridge(t *testing.T) {
 			Once().
 			Return(nil, nil)
 
+		sqsMock.onDelete(&sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(sqsQueueUrl),
+			Entries: []*sqs.DeleteMessageBatchRequestEntry{
+				{Id: aws.String("0"), ReceiptHandle: aws.String("receiptHandle")},
+			},
+		})
+
 		bridge := NewSqsBridge(routerMock, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock)
 		bridge.Run(bridgeCtx)
 
@@ -158,6 +180,433 @@ func Test_sqsBridge(t *testing.T) {
 
 		cancel()
 	})
+
+	t.Run("Creates Lambda SQS events", func(t *testing.T) {
+		t.Parallel()
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, "arn:aws:sqs:us-west-2:123456789012:SQSQueue", &sqsMock{}, "us-west-2", nil)
+
+		event, err := bridge.event([]*sqs.Message{
+			{
+				MessageId:              aws.String("MessageID_1"),
+				ReceiptHandle:          aws.String("MessageReceiptHandle"),
+				Body:                   aws.String("Message Body"),
+				MD5OfBody:              aws.String("fce0ea8dd236ccb3ed9b37dae260836f"),
+				MD5OfMessageAttributes: aws.String("582c92c5c5b6ac403040a4f3ab3115c9"),
+				Attributes: map[string]*string{
+					"ApproximateReceiveCount":          aws.String("2"),
+					"SentTimestamp":                    aws.String("1520621625029"),
+					"SenderId":                         aws.String("AROAIWPX5BD2BHG722MW4:sender"),
+					"ApproximateFirstReceiveTimestamp": aws.String("1520621634884"),
+				},
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{
+					"Attribute3": {
+						BinaryValue:      []byte("1100"),
+						StringListValues: aws.StringSlice([]string{"abc", "123"}),
+						BinaryListValues: [][]byte{[]byte("0"), []byte("1"), []byte("0")},
+						DataType:         aws.String("Binary"),
+					},
+					"Attribute2": {
+						StringValue:      aws.String("123"),
+						BinaryListValues: [][]byte{[]byte("1"), []byte("0")},
+						DataType:         aws.String("Number"),
+					},
+					"Attribute1": {
+						StringValue: aws.String("AttributeValue1"),
+						DataType:    aws.String("String"),
+					},
+				},
+			},
+		})
+
+		assert.Nil(t, err)
+
+		expected, err := os.ReadFile("testdata/sqs-event.json")
+		assert.Nil(t, err)
+
+		encoded, err := json.Marshal(event)
+		assert.Nil(t, err)
+
+		assert.JSONEq(t, string(expected), string(encoded))
+	})
+
+	t.Run("Passes FIFO attributes", func(t *testing.T) {
+		t.Parallel()
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, &sqsMock{}, awsRegionEuWest1, nil)
+
+		event, err := bridge.event([]*sqs.Message{
+			{
+				MessageId: aws.String("messageId"),
+				Body:      aws.String("body"),
+				Attributes: map[string]*string{
+					"ApproximateReceiveCount": aws.String("1"),
+					"MessageGroupId":          aws.String("group"),
+					"MessageDeduplicationId":  aws.String("deduplication"),
+					"SequenceNumber":          aws.String("18849496460467696128"),
+				},
+			},
+		})
+
+		assert.Nil(t, err)
+
+		encoded, err := json.Marshal(event)
+		assert.Nil(t, err)
+
+		request := events.SQSEvent{}
+		assert.Nil(t, json.Unmarshal(encoded, &request))
+
+		assert.Len(t, request.Records, 1)
+		assert.Equal(t, "aws:sqs", request.Records[0].EventSource)
+		assert.Equal(t, "group", request.Records[0].Attributes["MessageGroupId"])
+		assert.Equal(t, "deduplication", request.Records[0].Attributes["MessageDeduplicationId"])
+		assert.Equal(t, "1", request.Records[0].Attributes["ApproximateReceiveCount"])
+		assert.NotContains(t, event["Records"].([]interface{})[0], "md5OfMessageAttributes")
+	})
+
+	t.Run("Deletes handled messages in batches", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(12)
+
+		sqsMock := &sqsMock{}
+		sqsMock.onDelete(sqsDeleteInput(messages[:10]...))
+		sqsMock.onDelete(sqsDeleteInput(messages[10:]...))
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock)
+
+		assert.Nil(t, bridge.handleMessages(context.Background(), messages))
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Keeps the batch item failures on the queue", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(3)
+
+		sqsMock := &sqsMock{}
+		sqsMock.onDelete(sqsDeleteInput(messages[0], messages[2]))
+
+		routerMock := &routerMock{}
+		routerMock.
+			On("Handle", mock.Anything, mock.Anything).
+			Return(events.SQSEventResponse{
+				BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "message-1"}},
+			}, nil)
+
+		bridge := NewSqsBridge(routerMock, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock)
+
+		assert.Nil(t, bridge.handleMessages(context.Background(), messages))
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Keeps all messages if a batch item failure is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(2)
+		sqsMock := &sqsMock{}
+
+		routerMock := &routerMock{}
+		routerMock.
+			On("Handle", mock.Anything, mock.Anything).
+			Return(events.SQSEventResponse{
+				BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "unknown"}},
+			}, nil)
+
+		bridge := NewSqsBridge(routerMock, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock)
+
+		assert.Nil(t, bridge.handleMessages(context.Background(), messages))
+		sqsMock.AssertNotCalled(t, "DeleteMessageBatchWithContext", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Keeps all messages if the routing failed", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(2)
+		sqsMock := &sqsMock{}
+
+		routerMock := &routerMock{}
+		routerMock.
+			On("Handle", mock.Anything, mock.Anything).
+			Return(nil, errors.New("error"))
+
+		bridge := NewSqsBridge(routerMock, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock)
+
+		assert.EqualError(t, bridge.handleMessages(context.Background(), messages), "error")
+		sqsMock.AssertNotCalled(t, "DeleteMessageBatchWithContext", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Does not delete messages if disabled", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(2)
+		sqsMock := &sqsMock{}
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nilLoggerMock).
+			WithDeleteHandledMessages(false)
+
+		assert.Nil(t, bridge.handleMessages(context.Background(), messages))
+		sqsMock.AssertNotCalled(t, "DeleteMessageBatchWithContext", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Receives messages with the polling options", func(t *testing.T) {
+		t.Parallel()
+
+		sqsMock := &sqsMock{}
+		sqsMock.onReceive(sqsReceiveInput(5, 2, aws.Int64(30)), sqsMessages(2)...)
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithBatchSize(5).
+			WithWaitTime(2 * time.Second).
+			WithVisibilityTimeout(30 * time.Second)
+
+		messages, err := bridge.receiveMessages(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, messages, 2)
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Gathers messages within the batching window", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(3)
+
+		sqsMock := &sqsMock{}
+		sqsMock.onReceive(sqsReceiveInput(3, 20, nil), messages[:2]...)
+		sqsMock.onReceive(sqsReceiveInput(1, 20, nil), messages[2:]...)
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithBatchSize(3).
+			WithBatchingWindow(time.Minute)
+
+		received, err := bridge.receiveMessages(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, messages, received)
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Starts the batching window with the first message", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(3)
+		clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		sqsMock := &sqsMock{}
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, sqsReceiveInput(3, 20, nil), mock.Anything).
+			Once().
+			Run(func(mock.Arguments) { clock.now = clock.now.Add(15 * time.Second) }).
+			Return(&sqs.ReceiveMessageOutput{Messages: messages[:1]}, nil)
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, sqsReceiveInput(2, 10, nil), mock.Anything).
+			Once().
+			Run(func(mock.Arguments) { clock.now = clock.now.Add(10 * time.Second) }).
+			Return(&sqs.ReceiveMessageOutput{Messages: messages[1:2]}, nil)
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithBatchSize(3).
+			WithBatchingWindow(10 * time.Second).
+			WithClock(clock)
+
+		received, err := bridge.receiveMessages(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, messages[:2], received)
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Rounds the rest of the batching window up to a second", func(t *testing.T) {
+		t.Parallel()
+
+		messages := sqsMessages(2)
+		clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		sqsMock := &sqsMock{}
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, sqsReceiveInput(3, 20, nil), mock.Anything).
+			Once().
+			Return(&sqs.ReceiveMessageOutput{Messages: messages[:1]}, nil)
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, sqsReceiveInput(2, 1, nil), mock.Anything).
+			Once().
+			Run(func(mock.Arguments) { clock.now = clock.now.Add(time.Second) }).
+			Return(&sqs.ReceiveMessageOutput{Messages: messages[1:]}, nil)
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithBatchSize(3).
+			WithBatchingWindow(500 * time.Millisecond).
+			WithClock(clock)
+
+		received, err := bridge.receiveMessages(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, messages, received)
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Returns empty receives before the batching window starts", func(t *testing.T) {
+		t.Parallel()
+
+		sqsMock := &sqsMock{}
+		sqsMock.onReceive(sqsReceiveInput(10, 20, nil))
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithBatchingWindow(time.Minute)
+
+		received, err := bridge.receiveMessages(context.Background())
+
+		assert.Nil(t, err)
+		assert.Empty(t, received)
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Backs off after failed receives", func(t *testing.T) {
+		t.Parallel()
+
+		failingSqs := &failingSqsMock{}
+		bridgeCtx, cancel := context.WithCancel(context.Background())
+		clock := &sqsClock{delays: 4, cancel: cancel}
+
+		NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, failingSqs, awsRegionEuWest1, nil).
+			WithBackoff(20*time.Millisecond, 40*time.Millisecond).
+			WithClock(clock).
+			Run(bridgeCtx)
+
+		<-bridgeCtx.Done()
+
+		assert.Equal(
+			t,
+			[]time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond},
+			clock.waited(),
+		)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&failingSqs.calls))
+	})
+
+	t.Run("Waits for the poll interval after empty receives", func(t *testing.T) {
+		t.Parallel()
+
+		sqsMock := &sqsMock{}
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Times(2).
+			Return(&sqs.ReceiveMessageOutput{}, nil)
+
+		bridgeCtx, cancel := context.WithCancel(context.Background())
+		clock := &sqsClock{delays: 2, cancel: cancel}
+
+		NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithWaitTime(0).
+			WithPollInterval(5 * time.Second).
+			WithClock(clock).
+			Run(bridgeCtx)
+
+		<-bridgeCtx.Done()
+
+		assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, clock.waited())
+		sqsMock.AssertExpectations(t)
+	})
+
+	t.Run("Ignores invalid polling settings", func(t *testing.T) {
+		t.Parallel()
+
+		bridge := NewSqsBridge(nilRouter, sqsQueueUrl, sqsTargetArn, &sqsMock{}, awsRegionEuWest1, nilLoggerMock).
+			WithBatchSize(0).
+			WithMaximumConcurrency(-1).
+			WithWaitTime(time.Minute).
+			WithBackoff(0, time.Second).
+			WithPollInterval(-time.Second)
+
+		assert.Equal(t, 10, bridge.batchSize)
+		assert.Equal(t, 1, bridge.concurrency)
+		assert.Equal(t, 20*time.Second, bridge.waitTime)
+		assert.Equal(t, 100*time.Millisecond, bridge.backoff)
+		assert.Equal(t, 30*time.Second, bridge.maxBackoff)
+		assert.Equal(t, time.Second, bridge.pollInterval)
+		assert.Equal(t, time.Duration(0), bridge.WithWaitTime(-time.Second).waitTime)
+	})
+
+	t.Run("Handles batches with concurrent workers", func(t *testing.T) {
+		t.Parallel()
+
+		sqsMock := &sqsMock{}
+		sqsMock.
+			On("ReceiveMessageWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Return(&sqs.ReceiveMessageOutput{Messages: sqsMessages(1)}, nil)
+		sqsMock.
+			On("DeleteMessageBatchWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil)
+
+		started := make(chan struct{})
+		bridgeCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		routerMock := &routerMock{}
+		routerMock.
+			On("Handle", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				select {
+				case started <- struct{}{}:
+				case <-bridgeCtx.Done():
+				}
+
+				<-bridgeCtx.Done()
+			}).
+			Return(nil, nil)
+
+		NewSqsBridge(routerMock, sqsQueueUrl, sqsTargetArn, sqsMock, awsRegionEuWest1, nil).
+			WithMaximumConcurrency(3).
+			Run(bridgeCtx)
+
+		for i := 0; i < 3; i++ {
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				assert.Fail(t, "Batches are not handled concurrently")
+
+				return
+			}
+		}
+	})
+}
+
+func sqsMessages(count int) []*sqs.Message {
+	messages := make([]*sqs.Message, count)
+
+	for i := range messages {
+		messages[i] = &sqs.Message{
+			MessageId:     aws.String(fmt.Sprintf("message-%d", i)),
+			ReceiptHandle: aws.String(fmt.Sprintf("receipt-%d", i)),
+			Body:          aws.String("body"),
+		}
+	}
+
+	return messages
+}
+
+func sqsReceiveInput(maxMessages int64, waitTimeSeconds int64, visibilityTimeout *int64) *sqs.ReceiveMessageInput {
+	return &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(sqsQueueUrl),
+		AttributeNames:        aws.StringSlice([]string{"All"}),
+		MessageAttributeNames: aws.StringSlice([]string{"All"}),
+		MaxNumberOfMessages:   aws.Int64(maxMessages),
+		WaitTimeSeconds:       aws.Int64(waitTimeSeconds),
+		VisibilityTimeout:     visibilityTimeout,
+	}
+}
+
+func sqsDeleteInput(messages ...*sqs.Message) *sqs.DeleteMessageBatchInput {
+	entries := make([]*sqs.DeleteMessageBatchRequestEntry, len(messages))
+
+	for i, message := range messages {
+		entries[i] = &sqs.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: message.ReceiptHandle,
+		}
+	}
+
+	return &sqs.DeleteMessageBatchInput{QueueUrl: aws.String(sqsQueueUrl), Entries: entries}
 }
 
 type loggerMock struct {
@@ -192,6 +641,85 @@ func (m *sqsMock) ReceiveMessageWithContext(
 	return output, err
 }
 
+func (m *sqsMock) DeleteMessageBatchWithContext(
+	ctx aws.Context,
+	input *sqs.DeleteMessageBatchInput,
+	options ...request.Option,
+) (*sqs.DeleteMessageBatchOutput, error) {
+	args := m.Called(ctx, input, options)
+
+	return args.Get(0).(*sqs.DeleteMessageBatchOutput), args.Error(1)
+}
+
+func (m *sqsMock) onReceive(input *sqs.ReceiveMessageInput, messages ...*sqs.Message) {
+	m.
+		On("ReceiveMessageWithContext", mock.Anything, input, mock.Anything).
+		Once().
+		Return(&sqs.ReceiveMessageOutput{Messages: messages}, nil)
+}
+
+func (m *sqsMock) onDelete(input *sqs.DeleteMessageBatchInput) {
+	m.
+		On("DeleteMessageBatchWithContext", mock.Anything, input, mock.Anything).
+		Once().
+		Return(&sqs.DeleteMessageBatchOutput{}, nil)
+}
+
+// failingSqsMock fails all receives and counts them.
+type failingSqsMock struct {
+	sqsiface.SQSAPI
+	calls int32
+}
+
+func (m *failingSqsMock) ReceiveMessageWithContext(
+	ctx aws.Context,
+	input *sqs.ReceiveMessageInput,
+	options ...request.Option,
+) (*sqs.ReceiveMessageOutput, error) {
+	atomic.AddInt32(&m.calls, 1)
+
+	return nil, errors.New("error")
+}
+
+// sqsClock fires the delays immediately, records them and cancels the bridge after the given number of delays.
+type sqsClock struct {
+	mutex  sync.Mutex
+	delays int
+	cancel context.CancelFunc
+	delay  []time.Duration
+}
+
+func (clock *sqsClock) Now() time.Time {
+	return time.Now()
+}
+
+func (clock *sqsClock) After(d time.Duration) <-chan time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	fired := make(chan time.Time, 1)
+
+	if len(clock.delay) == clock.delays {
+		return fired
+	}
+
+	clock.delay = append(clock.delay, d)
+	fired <- time.Now()
+
+	if len(clock.delay) == clock.delays {
+		clock.cancel()
+	}
+
+	return fired
+}
+
+func (clock *sqsClock) waited() []time.Duration {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return append([]time.Duration{}, clock.delay...)
+}
+
 type routerMock struct {
 	mock.Mock
 }
@@ -210,7 +738,7 @@ func (m *routerMock) Handle(ctx context.Context, event map[string]interface{}) (
 	}
 
 	if args.Get(1) != nil {
-		resp = args.Error(1)
+		err = args.Error(1)
 	}
 
 	return resp, err
@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/joomcode/errorx"
+)
+
+var (
+	ClientErrors = errorx.NewNamespace("client")
+
+	ClientInvokeError    = ClientErrors.NewType("invoke")
+	ClientFunctionError  = ClientErrors.NewType("function")
+	ClientMarshalError   = ClientErrors.NewType("marshal")
+	ClientUnmarshalError = ClientErrors.NewType("unmarshal")
+
+	ClientErrorTypeProperty = errorx.RegisterProperty("error_type")
+)
+
+// LambdaInvoker is the subset of the lambdaiface.LambdaAPI used by the InvokeClient.
+type LambdaInvoker interface {
+	InvokeWithContext(ctx aws.Context, input *lambda.InvokeInput, opts ...request.Option) (*lambda.InvokeOutput, error)
+}
+
+// InvokeClient calls actions of the routes.InvokeRoute.
+type InvokeClient interface {
+	// Invoke calls the action with the payload and decodes the function response into the response,
+	// response can be nil if the response is not needed.
+	Invoke(ctx context.Context, action string, payload interface{}, response interface{}) error
+}
+
+type invokeClient struct {
+	lambda        LambdaInvoker
+	functionName  string
+	discriminator string
+}
+
+func NewInvokeClient(lambdaApi LambdaInvoker, functionName string, discriminator string) InvokeClient {
+	if discriminator == "" {
+		discriminator = routes.DefaultInvokeDiscriminator
+	}
+
+	return &invokeClient{
+		lambda:        lambdaApi,
+		functionName:  functionName,
+		discriminator: discriminator,
+	}
+}
+
+func (client *invokeClient) Invoke(ctx context.Context, action string, payload interface{}, response interface{}) error {
+	jsonPayload, err := json.Marshal(map[string]interface{}{
+		client.discriminator:      action,
+		routes.InvokePayloadField: payload,
+	})
+
+	if err != nil {
+		return ClientMarshalError.Wrap(err, "Failed to marshal payload to JSON")
+	}
+
+	output, err := client.lambda.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(client.functionName),
+		InvocationType: aws.String(lambda.InvocationTypeRequestResponse),
+		Payload:        jsonPayload,
+	})
+
+	if err != nil {
+		return ClientInvokeError.Wrap(err, "Failed to invoke %s", client.functionName)
+	}
+
+	if output.FunctionError != nil {
+		return functionError(output.Payload)
+	}
+
+	if response == nil || len(output.Payload) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(output.Payload, response)
+
+	if err != nil {
+		return ClientUnmarshalError.Wrap(err, "Failed to unmarshal response from the JSON")
+	}
+
+	return nil
+}
+
+// functionErrorPayload is the error format returned by the Lambda runtime.
+type functionErrorPayload struct {
+	ErrorMessage string `json:"errorMessage"`
+	ErrorType    string `json:"errorType"`
+}
+
+func functionError(payload []byte) error {
+	errPayload := functionErrorPayload{}
+
+	if err := json.Unmarshal(payload, &errPayload); err != nil {
+		return ClientFunctionError.New("Function failed with the unknown error: %s", payload)
+	}
+
+	return ClientFunctionError.
+		New("%s", errPayload.ErrorMessage).
+		WithProperty(ClientErrorTypeProperty, errPayload.ErrorType)
+}
@@ -0,0 +1,153 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	functionName = "function"
+)
+
+type resizeImageRequest struct {
+	Key string `json:"key"`
+}
+
+type resizeImageResponse struct {
+	Key string `json:"key"`
+}
+
+func Test_invokeClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Invokes function with the action and payload", func(t *testing.T) {
+		lambdaMock := &lambdaMock{}
+		lambdaMock.
+			On(
+				"InvokeWithContext",
+				mock.Anything,
+				&lambda.InvokeInput{
+					FunctionName:   aws.String(functionName),
+					InvocationType: aws.String(lambda.InvocationTypeRequestResponse),
+					Payload:        []byte(`{"command":"resizeImage","payload":{"key":"image.png"}}`),
+				},
+				mock.Anything,
+			).
+			Once().
+			Return(&lambda.InvokeOutput{Payload: []byte(`{"key":"image-100.png"}`)}, nil)
+
+		client := NewInvokeClient(lambdaMock, functionName, "command")
+		response := resizeImageResponse{}
+
+		err := client.Invoke(context.TODO(), "resizeImage", resizeImageRequest{Key: "image.png"}, &response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, resizeImageResponse{Key: "image-100.png"}, response)
+		lambdaMock.AssertExpectations(t)
+	})
+
+	t.Run("Returns an error if invoke failed", func(t *testing.T) {
+		lambdaMock := &lambdaMock{}
+		lambdaMock.
+			On("InvokeWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("error"))
+
+		err := NewInvokeClient(lambdaMock, functionName, "").Invoke(context.TODO(), "resizeImage", nil, nil)
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(ClientInvokeError))
+	})
+
+	t.Run("Returns function error", func(t *testing.T) {
+		lambdaMock := &lambdaMock{}
+		lambdaMock.
+			On("InvokeWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Return(&lambda.InvokeOutput{
+				FunctionError: aws.String("Unhandled"),
+				Payload:       []byte(`{"errorMessage":"Unknown action resizeImage","errorType":"Error"}`),
+			}, nil)
+
+		err := NewInvokeClient(lambdaMock, functionName, "").Invoke(context.TODO(), "resizeImage", nil, nil)
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(ClientFunctionError))
+		assert.Equal(t, "Unknown action resizeImage", err.(*errorx.Error).Message())
+
+		errorType, ok := errorx.ExtractProperty(err, ClientErrorTypeProperty)
+
+		assert.True(t, ok)
+		assert.Equal(t, "Error", errorType)
+	})
+
+	t.Run("Returns an error if response can not be unmarshalled", func(t *testing.T) {
+		lambdaMock := &lambdaMock{}
+		lambdaMock.
+			On("InvokeWithContext", mock.Anything, mock.Anything, mock.Anything).
+			Return(&lambda.InvokeOutput{Payload: []byte(`"string"`)}, nil)
+
+		err := NewInvokeClient(lambdaMock, functionName, "").
+			Invoke(context.TODO(), "resizeImage", nil, &resizeImageResponse{})
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(ClientUnmarshalError))
+	})
+
+	t.Run("Works with the router invoker", func(t *testing.T) {
+		router := routing.New().AddRoute(
+			routes.NewInvokeRoute("").AddAction(
+				"resizeImage",
+				resizeImageRequest{},
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					return resizeImageResponse{Key: request.(resizeImageRequest).Key + "-100"}, nil
+				},
+			),
+		)
+
+		client := NewInvokeClient(NewRouterInvoker(router), functionName, "")
+		response := resizeImageResponse{}
+
+		err := client.Invoke(context.TODO(), "resizeImage", resizeImageRequest{Key: "image"}, &response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, resizeImageResponse{Key: "image-100"}, response)
+
+		err = client.Invoke(context.TODO(), "unknown", nil, &response)
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(ClientFunctionError))
+		assert.Contains(t, err.(*errorx.Error).Message(), "Unknown action unknown")
+	})
+}
+
+type lambdaMock struct {
+	mock.Mock
+}
+
+func (m *lambdaMock) InvokeWithContext(
+	ctx aws.Context,
+	input *lambda.InvokeInput,
+	options ...request.Option,
+) (output *lambda.InvokeOutput, err error) {
+	args := m.Called(ctx, input, options)
+
+	if args.Get(0) != nil {
+		output = args.Get(0).(*lambda.InvokeOutput)
+	}
+
+	if args.Get(1) != nil {
+		err = args.Error(1)
+	}
+
+	return output, err
+}
@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+
+	routing "github.com/Napas/go-serverless-router"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+const (
+	functionErrorUnhandled = "Unhandled"
+	statusCodeOk           = 200
+)
+
+type routerInvoker struct {
+	router routing.Router
+}
+
+// NewRouterInvoker passes invocations directly to the router in the same process, the function name is ignored.
+// It's intended to be used for local development environments and tests only.
+func NewRouterInvoker(router routing.Router) LambdaInvoker {
+	return &routerInvoker{router: router}
+}
+
+func (invoker *routerInvoker) InvokeWithContext(
+	ctx aws.Context,
+	input *lambda.InvokeInput,
+	_ ...request.Option,
+) (*lambda.InvokeOutput, error) {
+	event := map[string]interface{}{}
+
+	if err := json.Unmarshal(input.Payload, &event); err != nil {
+		return nil, ClientUnmarshalError.Wrap(err, "Failed to unmarshal payload from the JSON")
+	}
+
+	resp, err := invoker.router.Handle(ctx, event)
+
+	if err != nil {
+		payload, _ := json.Marshal(functionErrorPayload{
+			ErrorMessage: err.Error(),
+			ErrorType:    fmt.Sprintf("%T", err),
+		})
+
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String(functionErrorUnhandled),
+			Payload:       payload,
+			StatusCode:    aws.Int64(statusCodeOk),
+		}, nil
+	}
+
+	payload, err := json.Marshal(resp)
+
+	if err != nil {
+		return nil, ClientMarshalError.Wrap(err, "Failed to marshal response to JSON")
+	}
+
+	return &lambda.InvokeOutput{
+		Payload:    payload,
+		StatusCode: aws.Int64(statusCodeOk),
+	}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
 
 	"github.com/joomcode/errorx"
 )
@@ -20,9 +21,61 @@ type Router interface {
 	Handle(ctx context.Context, event map[string]interface{}) (interface{}, error)
 }
 
+// CorsRouter is implemented by the routers created with New and NewWithLogger,
+// e.g. routing.New().(routing.CorsRouter).WithCors(policy).
+type CorsRouter interface {
+	Router
+	// WithCors adds CORS headers to the API Gateway responses and answers preflight requests
+	// which are not handled by a route of the OPTIONS method with the methods of the routes registered for the path.
+	WithCors(policy *routes.CorsPolicy) CorsRouter
+}
+
+// GroupRouter is implemented by the routers created with New and NewWithLogger and by their groups,
+// e.g. routing.New().(routing.GroupRouter).Group("/v1").
+type GroupRouter interface {
+	CorsRouter
+	// Group creates a sub-router which adds the path prefix and the middleware to its API Gateway routes,
+	// other routes are added as is. Groups use the CORS policy of the parent unless they have their own.
+	Group(prefix string, middleware ...routes.ApiGatewayMiddleware) GroupRouter
+	// Mount adds the routes of the router built elsewhere, e.g. with New, as if they were added to this router,
+	// the routes keep the CORS policies of the mounted router and its groups.
+	Mount(router Router) GroupRouter
+	// Routes returns the routes added to the router and its groups.
+	Routes() []routes.Route
+}
+
+// routeLister is implemented by the routers which can be mounted.
+type routeLister interface {
+	Routes() []routes.Route
+}
+
 type router struct {
-	routes []routes.Route
-	logger Logger
+	routes     []registeredRoute
+	logger     Logger
+	cors       *routes.CorsPolicy
+	parent     *router
+	prefix     string
+	middleware []routes.ApiGatewayMiddleware
+}
+
+// registeredRoute remembers the group which added the route to use its CORS policy.
+type registeredRoute struct {
+	route routes.Route
+	group *router
+}
+
+// leavesPreflight tells if the route handles any method but the preflight requests are answered by the CORS
+// policy of its group, otherwise the route would be called for them.
+func (registered registeredRoute) leavesPreflight() bool {
+	methodRoute, ok := registered.route.(apiGatewayMethodRoute)
+
+	return ok && methodRoute.HttpMethod() == routes.AnyHttpMethod && registered.group.corsPolicy() != nil
+}
+
+// apiGatewayMethodRoute is implemented by the routes.ApiGatewayRoute.
+type apiGatewayMethodRoute interface {
+	HttpMethod() string
+	MatchesPath(path string) bool
 }
 
 func New() Router {
@@ -34,27 +87,133 @@ func NewWithLogger(logger Logger) Router {
 }
 
 func (router *router) AddRoute(route routes.Route) Router {
-	router.routes = append(router.routes, route)
+	if apiGatewayRoute, ok := route.(*routes.ApiGatewayRoute); ok && router.parent != nil {
+		// inner groups first, so prefixes and middleware of the outer groups come first
+		for group := router; group.parent != nil; group = group.parent {
+			apiGatewayRoute = apiGatewayRoute.Grouped(group.prefix, group.middleware...)
+		}
+
+		route = apiGatewayRoute
+	}
+
+	root := router.root()
+	root.routes = append(root.routes, registeredRoute{route: route, group: router})
+
+	return router
+}
+
+func (router *router) WithCors(policy *routes.CorsPolicy) CorsRouter {
+	router.cors = policy
+
+	return router
+}
+
+func (parent *router) Group(prefix string, middleware ...routes.ApiGatewayMiddleware) GroupRouter {
+	return &router{
+		logger:     parent.logger,
+		parent:     parent,
+		prefix:     prefix,
+		middleware: middleware,
+	}
+}
+
+// Mount adds routes of the mounted router at the moment of the call, routes added to it later are not seen.
+// The group adds copies of the API Gateway routes, so the mounted router is not changed.
+// Routes of the groups with a CORS policy keep it, other routes use the policy of the group they are mounted into.
+func (router *router) Mount(mounted Router) GroupRouter {
+	if registered, ok := registeredRoutes(mounted); ok {
+		groups := map[*routes.CorsPolicy]GroupRouter{}
+
+		for _, route := range registered {
+			policy := route.group.corsPolicy()
+
+			if policy == nil {
+				router.AddRoute(route.route)
+
+				continue
+			}
+
+			if _, ok := groups[policy]; !ok {
+				groups[policy] = router.Group("").WithCors(policy).(GroupRouter)
+			}
+
+			groups[policy].AddRoute(route.route)
+		}
+
+		return router
+	}
+
+	lister, ok := mounted.(routeLister)
+
+	if !ok {
+		router.logger.Printf("Router %T can not be mounted, it does not list its routes", mounted)
+
+		return router
+	}
+
+	for _, route := range lister.Routes() {
+		router.AddRoute(route)
+	}
 
 	return router
 }
 
+func (router *router) Routes() []routes.Route {
+	added := []routes.Route{}
+
+	for _, registered := range router.root().routes {
+		if registered.group.isWithin(router) {
+			added = append(added, registered.route)
+		}
+	}
+
+	return added
+}
+
 func (router *router) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
-	encoded, _ := json.Marshal(event)
-	router.logger.Printf("Got event: %s", encoded)
+	if router.parent != nil {
+		return router.root().Handle(ctx, event)
+	}
+
+	if encoded, err := json.Marshal(event); err != nil {
+		router.logger.Printf("Got event which can not be encoded to JSON: %s", err.Error())
+	} else {
+		router.logger.Printf("Got event: %s", encoded)
+	}
+
+	request, isApiGateway := events.APIGatewayProxyRequest{}, false
+
+	// the request is needed only for the CORS headers and the preflight responses
+	if router.hasCors() {
+		request, isApiGateway = apiGatewayRequest(event)
+	}
+
+	isPreflight := isApiGateway && routes.IsCorsPreflight(request.HTTPMethod, request.Headers)
+
+	for _, registered := range router.routes {
+		route := registered.route
+
+		if isPreflight && registered.leavesPreflight() {
+			continue
+		}
 
-	for _, route := range router.routes {
 		if route.Matches(event) {
 			resp, err := route.Handle(ctx, event)
 
 			if route.HasResponse() {
-				return resp, err
+				return registered.group.decorate(request, isApiGateway, resp), err
 			}
 
 			return err, err
 		}
 	}
 
+	if isPreflight {
+		if resp, ok := router.preflight(request); ok {
+			return resp, nil
+		}
+	}
+
 	router.logger.Println("Route was not found")
 
 	err := RouterRouteNotFoundError.New("Route not found")
@@ -63,3 +222,153 @@ func (router *router) Handle(ctx context.Context, event map[string]interface{})
 	// or as a second.
 	return err, err
 }
+
+func (router *router) root() *router {
+	root := router
+
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	return root
+}
+
+func (router *router) isWithin(group *router) bool {
+	for current := router; current != nil; current = current.parent {
+		if current == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (router *router) corsPolicy() *routes.CorsPolicy {
+	for current := router; current != nil; current = current.parent {
+		if current.cors != nil {
+			return current.cors
+		}
+	}
+
+	return nil
+}
+
+// decorate adds CORS headers of the group to the API Gateway response.
+func (router *router) decorate(request events.APIGatewayProxyRequest, isApiGateway bool, resp interface{}) interface{} {
+	policy := router.corsPolicy()
+	response, ok := resp.(events.APIGatewayProxyResponse)
+
+	if policy == nil || !ok || !isApiGateway {
+		return resp
+	}
+
+	return policy.Decorate(request.Headers, response)
+}
+
+// preflight answers the preflight request with the methods of the routes registered for the path,
+// the CORS policy is taken from the group of the first of them.
+func (router *router) preflight(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, bool) {
+	var policy *routes.CorsPolicy
+	methods := []string{}
+
+	for _, registered := range router.routes {
+		methodRoute, ok := registered.route.(apiGatewayMethodRoute)
+
+		if !ok || !methodRoute.MatchesPath(request.Path) {
+			continue
+		}
+
+		if policy == nil {
+			policy = registered.group.corsPolicy()
+		}
+
+		method := methodRoute.HttpMethod()
+
+		if method == routes.AnyHttpMethod {
+			method = routes.HeaderValue(request.Headers, routes.AccessControlRequestMethodHeader)
+		}
+
+		if !hasMethod(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+
+	if policy == nil {
+		return events.APIGatewayProxyResponse{}, false
+	}
+
+	return policy.Preflight(request.Headers, methods), true
+}
+
+// hasCors checks if a CORS policy is set for any of the registered routes.
+func (router *router) hasCors() bool {
+	for _, registered := range router.routes {
+		if registered.group.corsPolicy() != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apiGatewayRequest reads the method, the path and the headers of the API Gateway proxy event.
+func apiGatewayRequest(event map[string]interface{}) (events.APIGatewayProxyRequest, bool) {
+	request := events.APIGatewayProxyRequest{Headers: map[string]string{}}
+
+	// REQUEST authorizer events also have httpMethod and path
+	if _, ok := event["methodArn"]; ok {
+		return request, false
+	}
+
+	httpMethod, ok := event["httpMethod"].(string)
+
+	if !ok {
+		return request, false
+	}
+
+	request.HTTPMethod = httpMethod
+	request.Path, _ = event["path"].(string)
+
+	switch headers := event["headers"].(type) {
+	case map[string]string:
+		request.Headers = headers
+	case map[string]interface{}:
+		for name, value := range headers {
+			if value, ok := value.(string); ok {
+				request.Headers[name] = value
+			}
+		}
+	}
+
+	return request, true
+}
+
+// registeredRoutes returns the routes added to the router created with New, NewWithLogger or Group
+// with the groups which added them.
+func registeredRoutes(mounted Router) ([]registeredRoute, bool) {
+	group, ok := mounted.(*router)
+
+	if !ok {
+		return nil, false
+	}
+
+	registered := []registeredRoute{}
+
+	for _, route := range group.root().routes {
+		if route.group.isWithin(group) {
+			registered = append(registered, route)
+		}
+	}
+
+	return registered, true
+}
+
+func hasMethod(methods []string, method string) bool {
+	for _, existing := range methods {
+		if existing == method {
+			return true
+		}
+	}
+
+	return false
+}
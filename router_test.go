@@ -3,11 +3,14 @@ package routing_test
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/joomcode/errorx"
 
 	goserverlessrouter "github.com/Napas/go-serverless-router"
+	"github.com/Napas/go-serverless-router/routes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -143,4 +146,306 @@ func Test_Router(t *testing.T) {
 		assert.Error(t, resp.(error))
 		assert.Equal(t, expectedError, resp.(error))
 	})
+
+	t.Run("CORS", func(t *testing.T) {
+		policy, err := routes.NewCorsPolicy("https://*.example.com")
+
+		assert.Nil(t, err)
+
+		getRoute, err := routes.NewApiGatewayRoute(
+			"^/users$",
+			http.MethodGet,
+			func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			},
+		)
+
+		assert.Nil(t, err)
+
+		postRoute, err := routes.NewApiGatewayRoute(
+			"^/users$",
+			http.MethodPost,
+			func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusCreated}, nil
+			},
+		)
+
+		assert.Nil(t, err)
+
+		router := goserverlessrouter.New().(goserverlessrouter.CorsRouter).WithCors(policy).
+			AddRoute(getRoute).
+			AddRoute(postRoute)
+
+		t.Run("Adds CORS headers to the responses", func(t *testing.T) {
+			resp, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodGet,
+				"path":       "/users",
+				"headers":    map[string]interface{}{"origin": "https://app.example.com"},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					routes.VaryHeader:                     routes.OriginHeader,
+					routes.AccessControlAllowOriginHeader: "https://app.example.com",
+				},
+			}, resp)
+		})
+
+		t.Run("Responds to preflight with the registered methods", func(t *testing.T) {
+			resp, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodOptions,
+				"path":       "/users",
+				"headers": map[string]interface{}{
+					"Origin":                        "https://app.example.com",
+					"Access-Control-Request-Method": http.MethodPost,
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.(events.APIGatewayProxyResponse).StatusCode)
+			assert.Equal(
+				t,
+				"GET, POST, OPTIONS",
+				resp.(events.APIGatewayProxyResponse).Headers[routes.AccessControlAllowMethodsHeader],
+			)
+		})
+
+		t.Run("Returns an error for preflight of unknown path", func(t *testing.T) {
+			_, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodOptions,
+				"path":       "/orders",
+				"headers": map[string]interface{}{
+					"Origin":                        "https://app.example.com",
+					"Access-Control-Request-Method": http.MethodGet,
+				},
+			})
+
+			assert.True(t, errorx.IsOfType(err, goserverlessrouter.RouterRouteNotFoundError))
+		})
+
+		t.Run("Responds to preflight for routes of any method", func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			anyRoute, err := routes.NewHttpHandlerRoute("^/orders$", routes.AnyHttpMethod, mux)
+
+			assert.Nil(t, err)
+
+			router := goserverlessrouter.New().(goserverlessrouter.CorsRouter).WithCors(policy).AddRoute(anyRoute)
+
+			resp, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodOptions,
+				"path":       "/orders",
+				"headers": map[string]interface{}{
+					"Origin":                        "https://app.example.com",
+					"Access-Control-Request-Method": http.MethodPut,
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNoContent, resp.(events.APIGatewayProxyResponse).StatusCode)
+			assert.Equal(
+				t,
+				"https://app.example.com",
+				resp.(events.APIGatewayProxyResponse).Headers[routes.AccessControlAllowOriginHeader],
+			)
+			assert.Equal(
+				t,
+				"PUT, OPTIONS",
+				resp.(events.APIGatewayProxyResponse).Headers[routes.AccessControlAllowMethodsHeader],
+			)
+
+			resp, err = router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodPut,
+				"path":       "/orders",
+				"headers":    map[string]interface{}{"Origin": "https://app.example.com"},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusAccepted, resp.(events.APIGatewayProxyResponse).StatusCode)
+		})
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		newRoute := func(path string, method string) *routes.ApiGatewayRoute {
+			route, err := routes.NewApiGatewayRoute(
+				path,
+				method,
+				func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					return events.APIGatewayProxyResponse{
+						StatusCode: http.StatusOK,
+						Headers:    request.Headers,
+						Body:       request.Path,
+					}, nil
+				},
+			)
+
+			assert.Nil(t, err)
+
+			return route
+		}
+
+		withHeader := func(name string) routes.ApiGatewayMiddleware {
+			return func(next routes.ApiGatewayHandlerFunc) routes.ApiGatewayHandlerFunc {
+				return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					request.Headers = map[string]string{name: "true"}
+
+					return next(ctx, request)
+				}
+			}
+		}
+
+		t.Run("Adds prefix and middleware of the nested groups", func(t *testing.T) {
+			router := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			router.
+				Group("/v1").
+				Group("/admin", withHeader("X-Admin")).
+				AddRoute(newRoute("^/users$", http.MethodGet))
+
+			resp, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodGet,
+				"path":       "/v1/admin/users",
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"X-Admin": "true"},
+				Body:       "/v1/admin/users",
+			}, resp)
+
+			_, err = router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodGet,
+				"path":       "/users",
+			})
+
+			assert.True(t, errorx.IsOfType(err, goserverlessrouter.RouterRouteNotFoundError))
+		})
+
+		t.Run("Mounts routes of another router", func(t *testing.T) {
+			users := goserverlessrouter.New().
+				AddRoute(newRoute("^/users$", http.MethodGet)).
+				AddRoute(newRoute("^/users$", http.MethodPost))
+
+			router := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			group := router.Group("/v1").Mount(users)
+
+			assert.Len(t, group.Routes(), 2)
+			assert.Len(t, router.Routes(), 2)
+
+			resp, err := router.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodPost,
+				"path":       "/v1/users",
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, "/v1/users", resp.(events.APIGatewayProxyResponse).Body)
+		})
+
+		t.Run("Does not change the mounted routes", func(t *testing.T) {
+			users := goserverlessrouter.New().AddRoute(newRoute("^/users$", http.MethodGet))
+
+			router := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			router.Group("/v1").Mount(users)
+			router.Group("/v2").Mount(users)
+
+			for _, path := range []string{"/v1/users", "/v2/users"} {
+				resp, err := router.Handle(context.TODO(), map[string]interface{}{
+					"httpMethod": http.MethodGet,
+					"path":       path,
+				})
+
+				assert.Nil(t, err)
+				assert.Equal(t, path, resp.(events.APIGatewayProxyResponse).Body)
+			}
+
+			resp, err := users.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodGet,
+				"path":       "/users",
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, "/users", resp.(events.APIGatewayProxyResponse).Body)
+		})
+
+		t.Run("Keeps CORS policies of the mounted router and its groups", func(t *testing.T) {
+			rootPolicy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			usersPolicy, err := routes.NewCorsPolicy("https://users.example.com")
+
+			assert.Nil(t, err)
+
+			adminPolicy, err := routes.NewCorsPolicy("https://admin.example.com")
+
+			assert.Nil(t, err)
+
+			users := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			users.WithCors(usersPolicy)
+			users.AddRoute(newRoute("^/users$", http.MethodGet))
+			users.Group("/admin").WithCors(adminPolicy).AddRoute(newRoute("^/users$", http.MethodGet))
+
+			router := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			router.WithCors(rootPolicy)
+			router.Group("/v1").Mount(users)
+			router.Group("/v2").Mount(goserverlessrouter.New().AddRoute(newRoute("^/users$", http.MethodGet)))
+
+			origin := func(path string, origin string) string {
+				resp, err := router.Handle(context.TODO(), map[string]interface{}{
+					"httpMethod": http.MethodGet,
+					"path":       path,
+					"headers":    map[string]interface{}{"Origin": origin},
+				})
+
+				assert.Nil(t, err)
+
+				return resp.(events.APIGatewayProxyResponse).Headers[routes.AccessControlAllowOriginHeader]
+			}
+
+			assert.Len(t, router.Routes(), 3)
+			assert.Equal(t, "", origin("/v1/users", "https://example.com"))
+			assert.Equal(t, "https://users.example.com", origin("/v1/users", "https://users.example.com"))
+			assert.Equal(t, "https://admin.example.com", origin("/v1/admin/users", "https://admin.example.com"))
+			assert.Equal(t, "https://example.com", origin("/v2/users", "https://example.com"))
+		})
+
+		t.Run("Uses CORS policy of the group or its parent", func(t *testing.T) {
+			rootPolicy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			adminPolicy, err := routes.NewCorsPolicy("https://admin.example.com")
+
+			assert.Nil(t, err)
+
+			router := goserverlessrouter.New().(goserverlessrouter.GroupRouter)
+			router.WithCors(rootPolicy)
+			router.Group("/public").AddRoute(newRoute("^/users$", http.MethodGet))
+			router.Group("/admin").WithCors(adminPolicy).AddRoute(newRoute("^/users$", http.MethodGet))
+
+			origin := func(path string, origin string) string {
+				resp, err := router.Handle(context.TODO(), map[string]interface{}{
+					"httpMethod": http.MethodOptions,
+					"path":       path,
+					"headers": map[string]interface{}{
+						"Origin":                        origin,
+						"Access-Control-Request-Method": http.MethodGet,
+					},
+				})
+
+				assert.Nil(t, err)
+
+				return resp.(events.APIGatewayProxyResponse).Headers[routes.AccessControlAllowOriginHeader]
+			}
+
+			assert.Equal(t, "https://example.com", origin("/public/users", "https://example.com"))
+			assert.Equal(t, "", origin("/admin/users", "https://example.com"))
+			assert.Equal(t, "https://admin.example.com", origin("/admin/users", "https://admin.example.com"))
+		})
+	})
 }
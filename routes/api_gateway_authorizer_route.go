@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	authorizerTypeToken   = "TOKEN"
+	authorizerTypeRequest = "REQUEST"
+)
+
+// apiGatewayAuthorizerRoute matches API Gateway custom authorizer events by the authorizer type and the method ARN.
+type apiGatewayAuthorizerRoute struct {
+	authorizerType string
+	methodArn      *regexp.Regexp
+}
+
+func newApiGatewayAuthorizerRoute(authorizerType string, methodArn string) (*apiGatewayAuthorizerRoute, error) {
+	compiledMethodArn, err := regexp.Compile(methodArn)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &apiGatewayAuthorizerRoute{
+		authorizerType: authorizerType,
+		methodArn:      compiledMethodArn,
+	}, nil
+}
+
+func (route *apiGatewayAuthorizerRoute) Matches(event map[string]interface{}) bool {
+	if event["type"] != route.authorizerType {
+		return false
+	}
+
+	methodArn, ok := event["methodArn"].(string)
+
+	if !ok || !route.methodArn.MatchString(methodArn) {
+		return false
+	}
+
+	return true
+}
+
+func (*apiGatewayAuthorizerRoute) HasResponse() bool {
+	return true
+}
+
+func (route *apiGatewayAuthorizerRoute) String() string {
+	return fmt.Sprintf("API Gateway %s authorizer for %s", route.authorizerType, route.methodArn.String())
+}
+
+type ApiGatewayTokenAuthorizerRoute struct {
+	*apiGatewayAuthorizerRoute
+	handler ApiGatewayTokenAuthorizerHandlerFunc
+}
+
+func NewApiGatewayTokenAuthorizerRoute(
+	methodArn string,
+	handler ApiGatewayTokenAuthorizerHandlerFunc,
+) (*ApiGatewayTokenAuthorizerRoute, error) {
+	route, err := newApiGatewayAuthorizerRoute(authorizerTypeToken, methodArn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApiGatewayTokenAuthorizerRoute{apiGatewayAuthorizerRoute: route, handler: handler}, nil
+}
+
+func (route *ApiGatewayTokenAuthorizerRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.APIGatewayCustomAuthorizerRequest{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type ApiGatewayRequestAuthorizerRoute struct {
+	*apiGatewayAuthorizerRoute
+	handler ApiGatewayRequestAuthorizerHandlerFunc
+}
+
+func NewApiGatewayRequestAuthorizerRoute(
+	methodArn string,
+	handler ApiGatewayRequestAuthorizerHandlerFunc,
+) (*ApiGatewayRequestAuthorizerRoute, error) {
+	route, err := newApiGatewayAuthorizerRoute(authorizerTypeRequest, methodArn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApiGatewayRequestAuthorizerRoute{apiGatewayAuthorizerRoute: route, handler: handler}, nil
+}
+
+func (route *ApiGatewayRequestAuthorizerRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.APIGatewayCustomAuthorizerRequestTypeRequest{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
@@ -0,0 +1,178 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	authorizerMethodArn = "arn:aws:execute-api:us-east-1:123456789012:api-id/stage/GET/path"
+)
+
+func Test_ApiGatewayAuthorizerRoutes(t *testing.T) {
+	t.Parallel()
+
+	tokenHandler := func(
+		ctx context.Context,
+		request events.APIGatewayCustomAuthorizerRequest,
+	) (events.APIGatewayCustomAuthorizerResponse, error) {
+		return events.APIGatewayCustomAuthorizerResponse{}, nil
+	}
+
+	requestHandler := func(
+		ctx context.Context,
+		request events.APIGatewayCustomAuthorizerRequestTypeRequest,
+	) (events.APIGatewayCustomAuthorizerResponse, error) {
+		return events.APIGatewayCustomAuthorizerResponse{}, nil
+	}
+
+	t.Run("Constructors return an error if invalid regexp is passed", func(t *testing.T) {
+		_, err := routes.NewApiGatewayTokenAuthorizerRoute("[invalid regexp", tokenHandler)
+
+		assert.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+
+		_, err = routes.NewApiGatewayRequestAuthorizerRoute("[invalid regexp", requestHandler)
+
+		assert.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+	})
+
+	t.Run("HasResponse returns true", func(t *testing.T) {
+		tokenRoute, err := routes.NewApiGatewayTokenAuthorizerRoute(".*", tokenHandler)
+
+		require.Nil(t, err)
+		assert.True(t, tokenRoute.HasResponse())
+
+		requestRoute, err := routes.NewApiGatewayRequestAuthorizerRoute(".*", requestHandler)
+
+		require.Nil(t, err)
+		assert.True(t, requestRoute.HasResponse())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		tokenRoute, err := routes.NewApiGatewayTokenAuthorizerRoute("^arn:aws:execute-api:us-east-1:123456789012:api-id\\/", tokenHandler)
+		require.Nil(t, err)
+
+		requestRoute, err := routes.NewApiGatewayRequestAuthorizerRoute("^arn:aws:execute-api:us-east-1:123456789012:api-id\\/", requestHandler)
+		require.Nil(t, err)
+
+		testCases := []struct {
+			description  string
+			event        map[string]interface{}
+			tokenMatch   bool
+			requestMatch bool
+		}{
+			{
+				description: "Empty event",
+				event:       map[string]interface{}{},
+			},
+			{
+				description: "Method ARN is not set",
+				event:       map[string]interface{}{"type": "TOKEN"},
+			},
+			{
+				description: "Method ARN does not match",
+				event: map[string]interface{}{
+					"type":      "TOKEN",
+					"methodArn": "arn:aws:execute-api:us-east-1:123456789012:another/stage/GET/path",
+				},
+			},
+			{
+				description: "TOKEN authorizer event",
+				event: map[string]interface{}{
+					"type":      "TOKEN",
+					"methodArn": authorizerMethodArn,
+				},
+				tokenMatch: true,
+			},
+			{
+				description: "REQUEST authorizer event",
+				event: map[string]interface{}{
+					"type":       "REQUEST",
+					"methodArn":  authorizerMethodArn,
+					"httpMethod": "GET",
+					"path":       "/path",
+				},
+				requestMatch: true,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				assert.Equal(t, testCase.tokenMatch, tokenRoute.Matches(testCase.event))
+				assert.Equal(t, testCase.requestMatch, requestRoute.Matches(testCase.event))
+			})
+		}
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Passes TOKEN authorizer request to the handler and returns its response", func(t *testing.T) {
+			requestCtx := context.TODO()
+			response := routes.NewAuthorizerPolicy("user").Allow(authorizerMethodArn).Response()
+
+			route, err := routes.NewApiGatewayTokenAuthorizerRoute(
+				".*",
+				func(
+					ctx context.Context,
+					request events.APIGatewayCustomAuthorizerRequest,
+				) (events.APIGatewayCustomAuthorizerResponse, error) {
+					assert.Equal(t, requestCtx, ctx)
+					assert.Equal(t, "Bearer token", request.AuthorizationToken)
+					assert.Equal(t, authorizerMethodArn, request.MethodArn)
+
+					return response, nil
+				},
+			)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(requestCtx, map[string]interface{}{
+				"type":               "TOKEN",
+				"authorizationToken": "Bearer token",
+				"methodArn":          authorizerMethodArn,
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, response, resp)
+		})
+
+		t.Run("Passes REQUEST authorizer request to the handler and returns its error", func(t *testing.T) {
+			handlerErr := errors.New("Unauthorized")
+
+			route, err := routes.NewApiGatewayRequestAuthorizerRoute(
+				".*",
+				func(
+					ctx context.Context,
+					request events.APIGatewayCustomAuthorizerRequestTypeRequest,
+				) (events.APIGatewayCustomAuthorizerResponse, error) {
+					assert.Equal(t, "token", request.Headers["Authorization"])
+					assert.Equal(t, "/path", request.Path)
+
+					return events.APIGatewayCustomAuthorizerResponse{}, handlerErr
+				},
+			)
+
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{
+				"type":       "REQUEST",
+				"methodArn":  authorizerMethodArn,
+				"path":       "/path",
+				"httpMethod": "GET",
+				"headers": map[string]interface{}{
+					"Authorization": "token",
+				},
+			})
+
+			assert.Equal(t, handlerErr, err)
+		})
+	})
+}
@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
 type ApiGatewayRoute struct {
 	path       *regexp.Regexp
+	pathPrefix string
 	httpMethod string
 	handler    ApiGatewayHandlerFunc
+	middleware []ApiGatewayMiddleware
+	validation *RequestValidation
+	problems   *ProblemMapper
 }
 
 func NewApiGatewayRoute(
@@ -33,12 +38,60 @@ func NewApiGatewayRoute(
 	}, nil
 }
 
+// WithValidation validates the request before calling the handler,
+// invalid requests are responded with 400 Bad Request listing all invalid fields.
+func (route *ApiGatewayRoute) WithValidation(validation RequestValidation) *ApiGatewayRoute {
+	route.validation = &validation
+
+	return route
+}
+
+// WithPathPrefix requires the request path to start with the prefix followed by "/" or the end of the path,
+// and matches the path regexp against the rest of the path. Prefixes added later are prepended.
+func (route *ApiGatewayRoute) WithPathPrefix(prefix string) *ApiGatewayRoute {
+	route.pathPrefix = prefix + route.pathPrefix
+
+	return route
+}
+
+// Grouped returns a copy of the route with the prefix and the middleware of the route group prepended,
+// the route itself is not changed.
+func (route *ApiGatewayRoute) Grouped(prefix string, middleware ...ApiGatewayMiddleware) *ApiGatewayRoute {
+	grouped := *route
+
+	return grouped.WithPathPrefix(prefix).WithMiddleware(middleware...)
+}
+
+// WithMiddleware wraps the request handling, the first middleware is called first and middleware added later
+// wraps the earlier one. Middlewares are called before the request validation and decoding,
+// they get the bad request and the problem responses, errors they return are mapped to problems too.
+func (route *ApiGatewayRoute) WithMiddleware(middleware ...ApiGatewayMiddleware) *ApiGatewayRoute {
+	route.middleware = append(append([]ApiGatewayMiddleware{}, middleware...), route.middleware...)
+
+	return route
+}
+
+// WithProblemMapper converts errors returned by the handler and bad requests into
+// application/problem+json responses instead of failing the invocation.
+func (route *ApiGatewayRoute) WithProblemMapper(mapper *ProblemMapper) *ApiGatewayRoute {
+	route.problems = mapper
+
+	return route
+}
+
 func (route *ApiGatewayRoute) Matches(event map[string]interface{}) bool {
-	if event["httpMethod"] != route.httpMethod {
+	// REQUEST authorizer events also have httpMethod and path
+	if _, ok := event["methodArn"]; ok {
+		return false
+	}
+
+	if route.httpMethod != AnyHttpMethod && event["httpMethod"] != route.httpMethod {
 		return false
 	}
 
-	if !route.path.MatchString(event["path"].(string)) {
+	path, ok := event["path"].(string)
+
+	if !ok || !route.MatchesPath(path) {
 		return false
 	}
 
@@ -59,7 +112,80 @@ func (route *ApiGatewayRoute) Handle(ctx context.Context, event map[string]inter
 		return events.APIGatewayProxyResponse{}, RouteUnmarshalError.Wrap(err, "Failed to unmarshal event from JSON")
 	}
 
-	return route.handler(ctx, request)
+	handler := route.handle
+
+	for i := len(route.middleware) - 1; i >= 0; i-- {
+		handler = route.middleware[i](handler)
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil && route.problems != nil {
+		return route.problems.Response(ctx, err), nil
+	}
+
+	return response, err
+}
+
+// handle validates the request and calls the handler, it's wrapped by the middleware.
+func (route *ApiGatewayRoute) handle(
+	ctx context.Context,
+	request events.APIGatewayProxyRequest,
+) (events.APIGatewayProxyResponse, error) {
+	if route.validation != nil {
+		errs := route.validation.validate(
+			request.PathParameters,
+			request.QueryStringParameters,
+			request.Headers,
+			request.Body,
+			request.IsBase64Encoded,
+		)
+
+		if len(errs) > 0 {
+			return route.badRequestResponse(ctx, RouteValidationError.Wrap(errs, "Validation failed"))
+		}
+	}
+
+	response, err := route.handler(ctx, request)
+
+	if badRequest, ok := err.(jsonApiBadRequest); ok {
+		return route.badRequestResponse(ctx, badRequest.err)
+	}
+
+	if err != nil && route.problems != nil {
+		return route.problems.Response(ctx, err), nil
+	}
+
+	return response, err
+}
+
+func (route *ApiGatewayRoute) badRequestResponse(ctx context.Context, err error) (events.APIGatewayProxyResponse, error) {
+	if route.problems != nil {
+		return route.problems.Response(ctx, BadRequestError.Wrap(err, "%s", jsonApiErrorMessage(err))), nil
+	}
+
+	return jsonApiErrorResponse(err)
+}
+
+// HttpMethod returns the method handled by the route.
+func (route *ApiGatewayRoute) HttpMethod() string {
+	return route.httpMethod
+}
+
+// MatchesPath checks if the route handles the path with any method.
+func (route *ApiGatewayRoute) MatchesPath(path string) bool {
+	if !strings.HasPrefix(path, route.pathPrefix) {
+		return false
+	}
+
+	rest := path[len(route.pathPrefix):]
+
+	// the prefix must end at a segment boundary, so /v1 does not match /v10/users
+	if route.pathPrefix != "" && rest != "" && !strings.HasSuffix(route.pathPrefix, "/") && !strings.HasPrefix(rest, "/") {
+		return false
+	}
+
+	return route.path.MatchString(rest)
 }
 
 func (*ApiGatewayRoute) HasResponse() bool {
@@ -67,5 +193,5 @@ func (*ApiGatewayRoute) HasResponse() bool {
 }
 
 func (route *ApiGatewayRoute) String() string {
-	return fmt.Sprintf("API Gateway route: %s %s", route.httpMethod, route.path.String())
+	return fmt.Sprintf("API Gateway route: %s %s%s", route.httpMethod, route.pathPrefix, route.path.String())
 }
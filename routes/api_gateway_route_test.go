@@ -66,6 +66,20 @@ func Test_ApiGatewayRoute(t *testing.T) {
 				}
 			})
 
+			t.Run("If event is a REQUEST authorizer event", func(t *testing.T) {
+				route, err := routes.NewApiGatewayRoute("/path", http.MethodGet, voidHandler)
+
+				assert.NoError(t, err)
+
+				event := make(map[string]interface{})
+				event["type"] = "REQUEST"
+				event["methodArn"] = "arn:aws:execute-api:us-east-1:123456789012:api-id/stage/GET/path"
+				event["httpMethod"] = http.MethodGet
+				event["path"] = "/path"
+
+				assert.False(t, route.Matches(event))
+			})
+
 			t.Run("If path does not match regexp", func(t *testing.T) {
 				route, err := routes.NewApiGatewayRoute("/abc", http.MethodGet, voidHandler)
 
@@ -90,9 +104,122 @@ func Test_ApiGatewayRoute(t *testing.T) {
 
 			assert.True(t, route.Matches(event))
 		})
+
+		t.Run("Matches the rest of the path after the prefix", func(t *testing.T) {
+			route, err := routes.NewApiGatewayRoute("^/users$", http.MethodGet, voidHandler)
+
+			assert.NoError(t, err)
+
+			route.WithPathPrefix("/admin").WithPathPrefix("/v1")
+
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1/admin/users"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/admin/users"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/users"}))
+		})
+
+		t.Run("Requires the prefix to end at a path segment", func(t *testing.T) {
+			route, err := routes.NewApiGatewayRoute("^(/users)?$", http.MethodGet, voidHandler)
+
+			assert.NoError(t, err)
+
+			route.WithPathPrefix("/v1")
+
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1"}))
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1/users"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1users"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v10/users"}))
+		})
+
+		t.Run("Grouped returns a copy of the route", func(t *testing.T) {
+			route, err := routes.NewApiGatewayRoute("^/users$", http.MethodGet, voidHandler)
+
+			assert.NoError(t, err)
+
+			grouped := route.Grouped("/v1")
+
+			assert.True(t, grouped.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1/users"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/v1/users"}))
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/users"}))
+		})
 	})
 
 	t.Run("Handle", func(t *testing.T) {
+		t.Run("Calls middleware in the given order", func(t *testing.T) {
+			calls := []string{}
+			middleware := func(name string) routes.ApiGatewayMiddleware {
+				return func(next routes.ApiGatewayHandlerFunc) routes.ApiGatewayHandlerFunc {
+					return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+						calls = append(calls, name)
+
+						return next(ctx, request)
+					}
+				}
+			}
+
+			route, err := routes.NewApiGatewayRoute(
+				"^/path$",
+				http.MethodGet,
+				func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					calls = append(calls, "handler")
+
+					return events.APIGatewayProxyResponse{}, nil
+				},
+			)
+
+			assert.NoError(t, err)
+
+			route.WithMiddleware(middleware("first"), middleware("second"))
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{"httpMethod": http.MethodGet, "path": "/path"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"first", "second", "handler"}, calls)
+		})
+
+		t.Run("Calls middleware before the validation", func(t *testing.T) {
+			validator, err := routes.NewJsonSchemaValidator(`{"type": "object", "required": ["name"]}`)
+			assert.NoError(t, err)
+
+			route, err := routes.NewApiGatewayRoute("^/path$", http.MethodPost, voidHandler)
+			assert.NoError(t, err)
+
+			statusCodes := []int{}
+			route.WithValidation(routes.RequestValidation{Body: validator}).WithMiddleware(
+				func(next routes.ApiGatewayHandlerFunc) routes.ApiGatewayHandlerFunc {
+					return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+						if request.Headers["Authorization"] == "" {
+							return events.APIGatewayProxyResponse{}, routes.UnauthorizedError.New("Missing authorization")
+						}
+
+						response, err := next(ctx, request)
+						statusCodes = append(statusCodes, response.StatusCode)
+
+						return response, err
+					}
+				},
+			).WithProblemMapper(routes.NewProblemMapper())
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodPost,
+				"path":       "/path",
+				"body":       `{}`,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnauthorized, resp.(events.APIGatewayProxyResponse).StatusCode)
+
+			resp, err = route.Handle(context.TODO(), map[string]interface{}{
+				"httpMethod": http.MethodPost,
+				"path":       "/path",
+				"headers":    map[string]interface{}{"Authorization": "token"},
+				"body":       `{}`,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+			assert.Equal(t, []int{http.StatusBadRequest}, statusCodes)
+		})
+
 		t.Run("Passes correct data to the handler", func(t *testing.T) {
 			requestContext := context.TODO()
 
@@ -142,5 +269,76 @@ func Test_ApiGatewayRoute(t *testing.T) {
 			assert.Equal(t, response, resp)
 			assert.Equal(t, responseErr, err)
 		})
+
+		t.Run("Responds 400 with all invalid fields if validation fails", func(t *testing.T) {
+			pathValidator, err := routes.NewStructValidator(struct {
+				Id int `json:"id" validate:"required"`
+			}{})
+			assert.NoError(t, err)
+
+			queryValidator, err := routes.NewJsonSchemaValidator(`{"properties": {"limit": {"type": "integer", "maximum": 100}}}`)
+			assert.NoError(t, err)
+
+			headersValidator, err := routes.NewStructValidator(struct {
+				Tenant string `json:"x-tenant" validate:"required"`
+			}{})
+			assert.NoError(t, err)
+
+			bodyValidator, err := routes.NewJsonSchemaValidator(`{"type": "object", "required": ["name"]}`)
+			assert.NoError(t, err)
+
+			called := false
+			route, err := routes.NewApiGatewayRoute(
+				"\\/path",
+				http.MethodPost,
+				func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+					called = true
+
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+				},
+			)
+			assert.NoError(t, err)
+
+			route.WithValidation(routes.RequestValidation{
+				PathParameters: pathValidator,
+				QueryStrings:   queryValidator,
+				Headers:        headersValidator,
+				Body:           bodyValidator,
+			})
+
+			event := map[string]interface{}{
+				"httpMethod":            http.MethodPost,
+				"path":                  "/path",
+				"pathParameters":        map[string]interface{}{"id": "abc"},
+				"queryStringParameters": map[string]interface{}{"limit": "1000"},
+				"headers":               map[string]interface{}{"X-Tenant": "tenant"},
+				"body":                  `{}`,
+			}
+
+			resp, err := route.Handle(context.TODO(), event)
+
+			assert.NoError(t, err)
+			assert.False(t, called)
+			assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+			assert.Equal(t, "application/json", resp.(events.APIGatewayProxyResponse).Headers["Content-Type"])
+			assert.JSONEq(t, `{
+  "message": "Validation failed",
+  "errors": [
+    {"in": "path", "field": "id", "message": "must be integer"},
+    {"in": "query", "field": "limit", "message": "must be at most 100"},
+    {"in": "body", "field": "name", "message": "is required"}
+  ]
+}`, resp.(events.APIGatewayProxyResponse).Body)
+
+			event["pathParameters"] = map[string]interface{}{"id": "1"}
+			event["queryStringParameters"] = map[string]interface{}{"limit": "10"}
+			event["body"] = `{"name": "john"}`
+
+			resp, err = route.Handle(context.TODO(), event)
+
+			assert.NoError(t, err)
+			assert.True(t, called)
+			assert.Equal(t, http.StatusOK, resp.(events.APIGatewayProxyResponse).StatusCode)
+		})
 	})
 }
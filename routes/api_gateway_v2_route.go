@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	apiGatewayV2PayloadVersion = "2.0"
+)
+
+// ApiGatewayV2Route handles the API Gateway v2 (HTTP API) proxy events of the payload version 2.0.
+type ApiGatewayV2Route struct {
+	path       *regexp.Regexp
+	httpMethod string
+	handler    ApiGatewayV2HandlerFunc
+}
+
+// NewApiGatewayV2Route matches the rawPath of the request against the path regexp,
+// AnyHttpMethod matches all methods.
+func NewApiGatewayV2Route(
+	path string,
+	httpMethod string,
+	handler ApiGatewayV2HandlerFunc,
+) (*ApiGatewayV2Route, error) {
+	compiledPath, err := regexp.Compile(path)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &ApiGatewayV2Route{
+		path:       compiledPath,
+		httpMethod: httpMethod,
+		handler:    handler,
+	}, nil
+}
+
+func (route *ApiGatewayV2Route) Matches(event map[string]interface{}) bool {
+	// Lambda authorizer events of the HTTP API have the same version and request context
+	if event["version"] != apiGatewayV2PayloadVersion || event["routeArn"] != nil {
+		return false
+	}
+
+	requestContext, _ := event["requestContext"].(map[string]interface{})
+	httpContext, _ := requestContext["http"].(map[string]interface{})
+	httpMethod, ok := httpContext["method"].(string)
+
+	if !ok || (route.httpMethod != AnyHttpMethod && httpMethod != route.httpMethod) {
+		return false
+	}
+
+	path, ok := event["rawPath"].(string)
+
+	return ok && route.path.MatchString(path)
+}
+
+func (route *ApiGatewayV2Route) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.APIGatewayV2HTTPRequest{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return events.APIGatewayV2HTTPResponse{}, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+func (*ApiGatewayV2Route) HasResponse() bool {
+	return true
+}
+
+func (route *ApiGatewayV2Route) String() string {
+	return fmt.Sprintf("API Gateway v2 route: %s %s", route.httpMethod, route.path.String())
+}
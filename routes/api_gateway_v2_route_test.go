@@ -0,0 +1,90 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApiGatewayV2Route(t *testing.T) {
+	t.Parallel()
+
+	voidHandler := func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{}, nil
+	}
+
+	v2Event := func(method string, path string) map[string]interface{} {
+		return map[string]interface{}{
+			"version":  "2.0",
+			"routeKey": "$default",
+			"rawPath":  path,
+			"requestContext": map[string]interface{}{
+				"http": map[string]interface{}{"method": method, "path": path},
+			},
+		}
+	}
+
+	t.Run("NewApiGatewayV2Route", func(t *testing.T) {
+		t.Run("Returns an error if path does not compile to regexp", func(t *testing.T) {
+			_, err := routes.NewApiGatewayV2Route("[invalid regexp", http.MethodGet, voidHandler)
+
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		route, err := routes.NewApiGatewayV2Route("^\\/users\\/\\d+$", http.MethodGet, voidHandler)
+		require.Nil(t, err)
+
+		anyRoute, err := routes.NewApiGatewayV2Route("^\\/users\\/\\d+$", routes.AnyHttpMethod, voidHandler)
+		require.Nil(t, err)
+
+		authorizerEvent := v2Event(http.MethodGet, "/users/1")
+		authorizerEvent["type"] = "REQUEST"
+		authorizerEvent["routeArn"] = "arn:aws:execute-api:us-east-1:123456789012:api-id/$default/GET/users/1"
+
+		testCases := []struct {
+			description string
+			route       *routes.ApiGatewayV2Route
+			event       map[string]interface{}
+			matches     bool
+		}{
+			{"Method and path match", route, v2Event(http.MethodGet, "/users/1"), true},
+			{"Any method", anyRoute, v2Event(http.MethodDelete, "/users/1"), true},
+			{"Method does not match", route, v2Event(http.MethodPost, "/users/1"), false},
+			{"Path does not match", route, v2Event(http.MethodGet, "/users/me"), false},
+			{"v1 event", route, map[string]interface{}{"httpMethod": http.MethodGet, "path": "/users/1"}, false},
+			{"Lambda authorizer event", route, authorizerEvent, false},
+		}
+
+		for _, testCase := range testCases {
+			assert.Equal(t, testCase.matches, testCase.route.Matches(testCase.event), testCase.description)
+		}
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Passes the request to the handler", func(t *testing.T) {
+			route, err := routes.NewApiGatewayV2Route(
+				"^\\/users\\/\\d+$",
+				http.MethodGet,
+				func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+					return events.APIGatewayV2HTTPResponse{
+						StatusCode: http.StatusOK,
+						Body:       request.RequestContext.HTTP.Method + " " + request.RawPath,
+					}, nil
+				},
+			)
+			require.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), v2Event(http.MethodGet, "/users/1"))
+
+			require.Nil(t, err)
+			assert.Equal(t, events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK, Body: "GET /users/1"}, resp)
+		})
+	})
+}
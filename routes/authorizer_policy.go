@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	authorizerPolicyVersion = "2012-10-17"
+	authorizerPolicyAction  = "execute-api:Invoke"
+	authorizerPolicyAllow   = "Allow"
+	authorizerPolicyDeny    = "Deny"
+)
+
+// AuthorizerPolicy builds API Gateway custom authorizer responses.
+type AuthorizerPolicy struct {
+	principalId string
+	statements  []events.IAMPolicyStatement
+	context     map[string]interface{}
+}
+
+func NewAuthorizerPolicy(principalId string) *AuthorizerPolicy {
+	return &AuthorizerPolicy{principalId: principalId}
+}
+
+// Allow allows to invoke given method ARNs, e.g. arn:aws:execute-api:us-east-1:123456789012:api-id/stage/GET/path
+func (policy *AuthorizerPolicy) Allow(methodArns ...string) *AuthorizerPolicy {
+	return policy.addStatement(authorizerPolicyAllow, methodArns)
+}
+
+// Deny denies to invoke given method ARNs, deny always takes precedence over allow.
+func (policy *AuthorizerPolicy) Deny(methodArns ...string) *AuthorizerPolicy {
+	return policy.addStatement(authorizerPolicyDeny, methodArns)
+}
+
+// WithContext adds a value to the context which is passed to the backend in the requestContext.authorizer.
+// API Gateway only accepts string, number and boolean values.
+func (policy *AuthorizerPolicy) WithContext(key string, value interface{}) *AuthorizerPolicy {
+	if policy.context == nil {
+		policy.context = map[string]interface{}{}
+	}
+
+	policy.context[key] = value
+
+	return policy
+}
+
+func (policy *AuthorizerPolicy) Response() events.APIGatewayCustomAuthorizerResponse {
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: policy.principalId,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version:   authorizerPolicyVersion,
+			Statement: policy.statements,
+		},
+		Context: policy.context,
+	}
+}
+
+func (policy *AuthorizerPolicy) addStatement(effect string, methodArns []string) *AuthorizerPolicy {
+	if len(methodArns) == 0 {
+		return policy
+	}
+
+	policy.statements = append(policy.statements, events.IAMPolicyStatement{
+		Action:   []string{authorizerPolicyAction},
+		Effect:   effect,
+		Resource: methodArns,
+	})
+
+	return policy
+}
@@ -0,0 +1,55 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuthorizerPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Returns response with principal ID and no statements", func(t *testing.T) {
+		resp := routes.NewAuthorizerPolicy("user").Response()
+
+		assert.Equal(t, "user", resp.PrincipalID)
+		assert.Equal(t, "2012-10-17", resp.PolicyDocument.Version)
+		assert.Empty(t, resp.PolicyDocument.Statement)
+		assert.Nil(t, resp.Context)
+	})
+
+	t.Run("Adds allow and deny statements", func(t *testing.T) {
+		resp := routes.NewAuthorizerPolicy("user").
+			Allow("arn:1", "arn:2").
+			Deny("arn:3").
+			Allow().
+			Response()
+
+		assert.Equal(t, []events.IAMPolicyStatement{
+			{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   "Allow",
+				Resource: []string{"arn:1", "arn:2"},
+			},
+			{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   "Deny",
+				Resource: []string{"arn:3"},
+			},
+		}, resp.PolicyDocument.Statement)
+	})
+
+	t.Run("Adds context values", func(t *testing.T) {
+		resp := routes.NewAuthorizerPolicy("user").
+			WithContext("userId", "123").
+			WithContext("admin", true).
+			Response()
+
+		assert.Equal(t, map[string]interface{}{
+			"userId": "123",
+			"admin":  true,
+		}, resp.Context)
+	})
+}
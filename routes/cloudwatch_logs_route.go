@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	cloudWatchLogsControlMessage = "CONTROL_MESSAGE"
+)
+
+// CloudWatchLogsRoute matches CloudWatch Logs subscription events.
+// Events are decoded from the base64 gzipped payload before being passed to the handler,
+// CONTROL_MESSAGE health checks are acknowledged without calling the handler.
+type CloudWatchLogsRoute struct {
+	logGroup  *regexp.Regexp
+	logStream *regexp.Regexp
+	handler   CloudWatchLogsHandlerFunc
+}
+
+func NewCloudWatchLogsRoute(
+	logGroup string,
+	logStream string,
+	handler CloudWatchLogsHandlerFunc,
+) (*CloudWatchLogsRoute, error) {
+	compiledLogGroup, err := regexp.Compile(logGroup)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid log group regexp given")
+	}
+
+	compiledLogStream, err := regexp.Compile(logStream)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid log stream regexp given")
+	}
+
+	return &CloudWatchLogsRoute{
+		logGroup:  compiledLogGroup,
+		logStream: compiledLogStream,
+		handler:   handler,
+	}, nil
+}
+
+func (route *CloudWatchLogsRoute) Matches(event map[string]interface{}) bool {
+	data, err := decodeCloudWatchLogsData(event)
+
+	if err != nil {
+		return false
+	}
+
+	if data.MessageType == cloudWatchLogsControlMessage {
+		return true
+	}
+
+	if !route.logGroup.MatchString(data.LogGroup) {
+		return false
+	}
+
+	if !route.logStream.MatchString(data.LogStream) {
+		return false
+	}
+
+	return true
+}
+
+func (route *CloudWatchLogsRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	data, err := decodeCloudWatchLogsData(event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data.MessageType == cloudWatchLogsControlMessage {
+		return nil, nil
+	}
+
+	return nil, route.handler(ctx, data)
+}
+
+func (*CloudWatchLogsRoute) HasResponse() bool {
+	return false
+}
+
+func (route *CloudWatchLogsRoute) String() string {
+	return fmt.Sprintf(
+		"CloudWatch Logs subscription for log group %s and log stream %s",
+		route.logGroup.String(),
+		route.logStream.String(),
+	)
+}
+
+func decodeCloudWatchLogsData(event map[string]interface{}) (events.CloudwatchLogsData, error) {
+	awsLogs, ok := event["awslogs"].(map[string]interface{})
+
+	if !ok {
+		return events.CloudwatchLogsData{}, RouteUnmarshalError.New("Event has no awslogs key")
+	}
+
+	data, ok := awsLogs["data"].(string)
+
+	if !ok {
+		return events.CloudwatchLogsData{}, RouteUnmarshalError.New("Event has no awslogs data")
+	}
+
+	decoded, err := events.CloudwatchLogsRawData{Data: data}.Parse()
+
+	if err != nil {
+		return events.CloudwatchLogsData{}, RouteUnmarshalError.Wrap(err, "Failed to decode awslogs data")
+	}
+
+	return decoded, nil
+}
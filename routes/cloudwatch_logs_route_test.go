@@ -0,0 +1,217 @@
+package routes_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CloudWatchLogsRoute(t *testing.T) {
+	t.Parallel()
+
+	nilHandler := func(ctx context.Context, request events.CloudwatchLogsData) error {
+		return nil
+	}
+
+	t.Run("NewCloudWatchLogsRoute", func(t *testing.T) {
+		t.Run("Returns an error if log group regexp is invalid", func(t *testing.T) {
+			_, err := routes.NewCloudWatchLogsRoute("[invalid regexp", ".*", nilHandler)
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+		})
+
+		t.Run("Returns an error if log stream regexp is invalid", func(t *testing.T) {
+			_, err := routes.NewCloudWatchLogsRoute(".*", "[invalid regexp", nilHandler)
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+		})
+	})
+
+	t.Run("HasResponse returns false", func(t *testing.T) {
+		route, err := routes.NewCloudWatchLogsRoute(".*", ".*", nilHandler)
+
+		require.Nil(t, err)
+		assert.False(t, route.HasResponse())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			event       map[string]interface{}
+			expected    bool
+		}{
+			{
+				description: "Empty event",
+				event:       map[string]interface{}{},
+				expected:    false,
+			},
+			{
+				description: "Data is not encoded",
+				event: map[string]interface{}{
+					"awslogs": map[string]interface{}{"data": "not base64 gzip"},
+				},
+				expected: false,
+			},
+			{
+				description: "Log group does not match",
+				event: cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+					MessageType: "DATA_MESSAGE",
+					LogGroup:    "/aws/lambda/another",
+					LogStream:   "2019/01/01/[$LATEST]abc",
+				}),
+				expected: false,
+			},
+			{
+				description: "Log stream does not match",
+				event: cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+					MessageType: "DATA_MESSAGE",
+					LogGroup:    "/aws/lambda/function",
+					LogStream:   "another",
+				}),
+				expected: false,
+			},
+			{
+				description: "Log group and log stream matches",
+				event: cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+					MessageType: "DATA_MESSAGE",
+					LogGroup:    "/aws/lambda/function",
+					LogStream:   "2019/01/01/[$LATEST]abc",
+				}),
+				expected: true,
+			},
+			{
+				description: "Control message",
+				event: cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+					MessageType: "CONTROL_MESSAGE",
+				}),
+				expected: true,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				route, err := routes.NewCloudWatchLogsRoute("^\\/aws\\/lambda\\/function$", "^\\d{4}\\/", nilHandler)
+
+				require.Nil(t, err)
+				assert.Equal(t, testCase.expected, route.Matches(testCase.event))
+			})
+		}
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Passes decoded data to the handler", func(t *testing.T) {
+			requestCtx := context.TODO()
+			data := events.CloudwatchLogsData{
+				Owner:               "123456789012",
+				LogGroup:            "/aws/lambda/function",
+				LogStream:           "stream",
+				SubscriptionFilters: []string{"filter"},
+				MessageType:         "DATA_MESSAGE",
+				LogEvents: []events.CloudwatchLogsLogEvent{
+					{ID: "id", Timestamp: 1559762747, Message: "message"},
+				},
+			}
+
+			route, err := routes.NewCloudWatchLogsRoute(
+				".*",
+				".*",
+				func(ctx context.Context, request events.CloudwatchLogsData) error {
+					assert.Equal(t, requestCtx, ctx)
+					assert.Equal(t, data, request)
+
+					return nil
+				},
+			)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(requestCtx, cloudWatchLogsEvent(t, data))
+
+			assert.Nil(t, err)
+			assert.Nil(t, resp)
+		})
+
+		t.Run("Returns an error from the handler", func(t *testing.T) {
+			handlerErr := errors.New("error")
+
+			route, err := routes.NewCloudWatchLogsRoute(
+				".*",
+				".*",
+				func(ctx context.Context, request events.CloudwatchLogsData) error {
+					return handlerErr
+				},
+			)
+
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+				MessageType: "DATA_MESSAGE",
+			}))
+
+			assert.Equal(t, handlerErr, err)
+		})
+
+		t.Run("Skips control messages", func(t *testing.T) {
+			route, err := routes.NewCloudWatchLogsRoute(
+				".*",
+				".*",
+				func(ctx context.Context, request events.CloudwatchLogsData) error {
+					assert.Fail(t, "Handler should not be called")
+
+					return nil
+				},
+			)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), cloudWatchLogsEvent(t, events.CloudwatchLogsData{
+				MessageType: "CONTROL_MESSAGE",
+			}))
+
+			assert.Nil(t, err)
+			assert.Nil(t, resp)
+		})
+
+		t.Run("Returns an error if data can not be decoded", func(t *testing.T) {
+			route, err := routes.NewCloudWatchLogsRoute(".*", ".*", nilHandler)
+
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{
+				"awslogs": map[string]interface{}{"data": "not base64 gzip"},
+			})
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnmarshalError))
+		})
+	})
+}
+
+func cloudWatchLogsEvent(t *testing.T, data events.CloudwatchLogsData) map[string]interface{} {
+	jsonData, err := json.Marshal(data)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	_, err = writer.Write(jsonData)
+	require.Nil(t, err)
+	require.Nil(t, writer.Close())
+
+	return map[string]interface{}{
+		"awslogs": map[string]interface{}{
+			"data": base64.StdEncoding.EncodeToString(buf.Bytes()),
+		},
+	}
+}
@@ -0,0 +1,314 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+	cognitoPreSignupTriggerSources = []string{
+		"PreSignUp_SignUp",
+		"PreSignUp_AdminCreateUser",
+		"PreSignUp_ExternalProvider",
+	}
+	cognitoPostConfirmationTriggerSources = []string{
+		"PostConfirmation_ConfirmSignUp",
+		"PostConfirmation_ConfirmForgotPassword",
+	}
+	cognitoPreTokenGenTriggerSources = []string{
+		"TokenGeneration_HostedAuth",
+		"TokenGeneration_Authentication",
+		"TokenGeneration_NewPasswordChallenge",
+		"TokenGeneration_AuthenticateDevice",
+		"TokenGeneration_RefreshTokens",
+	}
+	cognitoCustomMessageTriggerSources = []string{
+		"CustomMessage_SignUp",
+		"CustomMessage_AdminCreateUser",
+		"CustomMessage_ResendCode",
+		"CustomMessage_ForgotPassword",
+		"CustomMessage_UpdateUserAttribute",
+		"CustomMessage_VerifyUserAttribute",
+		"CustomMessage_Authentication",
+	}
+	cognitoDefineAuthChallengeTriggerSources = []string{
+		"DefineAuthChallenge_Authentication",
+	}
+	cognitoCreateAuthChallengeTriggerSources = []string{
+		"CreateAuthChallenge_Authentication",
+	}
+	cognitoVerifyAuthChallengeTriggerSources = []string{
+		"VerifyAuthChallengeResponse_Authentication",
+	}
+	cognitoMigrateUserTriggerSources = []string{
+		"UserMigration_Authentication",
+		"UserMigration_ForgotPassword",
+	}
+)
+
+// cognitoRoute matches Cognito User Pool trigger events by the trigger family and the user pool ID.
+// Cognito expects the event to be echoed back with the modified response, so all Cognito routes have a response.
+type cognitoRoute struct {
+	userPoolId     *regexp.Regexp
+	triggerSources []string
+}
+
+func newCognitoRoute(userPoolId string, triggerSources []string) (*cognitoRoute, error) {
+	compiledUserPoolId, err := regexp.Compile(userPoolId)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &cognitoRoute{
+		userPoolId:     compiledUserPoolId,
+		triggerSources: triggerSources,
+	}, nil
+}
+
+func (route *cognitoRoute) Matches(event map[string]interface{}) bool {
+	triggerSource, ok := event["triggerSource"].(string)
+
+	if !ok || !hasString(route.triggerSources, triggerSource) {
+		return false
+	}
+
+	userPoolId, ok := event["userPoolId"].(string)
+
+	if !ok || !route.userPoolId.MatchString(userPoolId) {
+		return false
+	}
+
+	return true
+}
+
+func (*cognitoRoute) HasResponse() bool {
+	return true
+}
+
+func (route *cognitoRoute) String() string {
+	return fmt.Sprintf(
+		"Cognito trigger %s for user pool %s",
+		strings.Join(route.triggerSources, ", "),
+		route.userPoolId.String(),
+	)
+}
+
+type CognitoPreSignupRoute struct {
+	*cognitoRoute
+	handler CognitoPreSignupHandlerFunc
+}
+
+func NewCognitoPreSignupRoute(userPoolId string, handler CognitoPreSignupHandlerFunc) (*CognitoPreSignupRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoPreSignupTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoPreSignupRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoPreSignupRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsPreSignup{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoPostConfirmationRoute struct {
+	*cognitoRoute
+	handler CognitoPostConfirmationHandlerFunc
+}
+
+func NewCognitoPostConfirmationRoute(
+	userPoolId string,
+	handler CognitoPostConfirmationHandlerFunc,
+) (*CognitoPostConfirmationRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoPostConfirmationTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoPostConfirmationRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoPostConfirmationRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsPostConfirmation{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoPreTokenGenRoute struct {
+	*cognitoRoute
+	handler CognitoPreTokenGenHandlerFunc
+}
+
+func NewCognitoPreTokenGenRoute(userPoolId string, handler CognitoPreTokenGenHandlerFunc) (*CognitoPreTokenGenRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoPreTokenGenTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoPreTokenGenRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoPreTokenGenRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsPreTokenGen{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoCustomMessageRoute struct {
+	*cognitoRoute
+	handler CognitoCustomMessageHandlerFunc
+}
+
+func NewCognitoCustomMessageRoute(
+	userPoolId string,
+	handler CognitoCustomMessageHandlerFunc,
+) (*CognitoCustomMessageRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoCustomMessageTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoCustomMessageRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoCustomMessageRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsCustomMessage{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoDefineAuthChallengeRoute struct {
+	*cognitoRoute
+	handler CognitoDefineAuthChallengeHandlerFunc
+}
+
+func NewCognitoDefineAuthChallengeRoute(
+	userPoolId string,
+	handler CognitoDefineAuthChallengeHandlerFunc,
+) (*CognitoDefineAuthChallengeRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoDefineAuthChallengeTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoDefineAuthChallengeRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoDefineAuthChallengeRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsDefineAuthChallenge{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoCreateAuthChallengeRoute struct {
+	*cognitoRoute
+	handler CognitoCreateAuthChallengeHandlerFunc
+}
+
+func NewCognitoCreateAuthChallengeRoute(
+	userPoolId string,
+	handler CognitoCreateAuthChallengeHandlerFunc,
+) (*CognitoCreateAuthChallengeRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoCreateAuthChallengeTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoCreateAuthChallengeRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoCreateAuthChallengeRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsCreateAuthChallenge{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoVerifyAuthChallengeRoute struct {
+	*cognitoRoute
+	handler CognitoVerifyAuthChallengeHandlerFunc
+}
+
+func NewCognitoVerifyAuthChallengeRoute(
+	userPoolId string,
+	handler CognitoVerifyAuthChallengeHandlerFunc,
+) (*CognitoVerifyAuthChallengeRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoVerifyAuthChallengeTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoVerifyAuthChallengeRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoVerifyAuthChallengeRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsVerifyAuthChallenge{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
+
+type CognitoMigrateUserRoute struct {
+	*cognitoRoute
+	handler CognitoMigrateUserHandlerFunc
+}
+
+func NewCognitoMigrateUserRoute(userPoolId string, handler CognitoMigrateUserHandlerFunc) (*CognitoMigrateUserRoute, error) {
+	route, err := newCognitoRoute(userPoolId, cognitoMigrateUserTriggerSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CognitoMigrateUserRoute{cognitoRoute: route, handler: handler}, nil
+}
+
+func (route *CognitoMigrateUserRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := events.CognitoEventUserPoolsMigrateUser{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	return route.handler(ctx, request)
+}
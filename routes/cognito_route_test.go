@@ -0,0 +1,245 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cognitoUserPoolId = "us-east-1_abcdef"
+)
+
+func Test_CognitoRoutes(t *testing.T) {
+	t.Parallel()
+
+	preSignupHandler := func(
+		ctx context.Context,
+		request events.CognitoEventUserPoolsPreSignup,
+	) (events.CognitoEventUserPoolsPreSignup, error) {
+		return request, nil
+	}
+
+	t.Run("Returns an error if invalid regexp is passed", func(t *testing.T) {
+		_, err := routes.NewCognitoPreSignupRoute("[invalid regexp", preSignupHandler)
+
+		assert.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+	})
+
+	t.Run("HasResponse returns true", func(t *testing.T) {
+		route, err := routes.NewCognitoPreSignupRoute(".*", preSignupHandler)
+
+		require.Nil(t, err)
+		assert.True(t, route.HasResponse())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		t.Run("Returns false", func(t *testing.T) {
+			testCases := []struct {
+				description string
+				event       map[string]interface{}
+			}{
+				{
+					description: "Empty event",
+					event:       map[string]interface{}{},
+				},
+				{
+					description: "Trigger source of the another family",
+					event: map[string]interface{}{
+						"triggerSource": "PostConfirmation_ConfirmSignUp",
+						"userPoolId":    cognitoUserPoolId,
+					},
+				},
+				{
+					description: "User pool ID is not set",
+					event: map[string]interface{}{
+						"triggerSource": "PreSignUp_SignUp",
+					},
+				},
+				{
+					description: "User pool ID does not match",
+					event: map[string]interface{}{
+						"triggerSource": "PreSignUp_SignUp",
+						"userPoolId":    "eu-west-1_another",
+					},
+				},
+			}
+
+			for _, testCase := range testCases {
+				t.Run(testCase.description, func(t *testing.T) {
+					route, err := routes.NewCognitoPreSignupRoute("^"+cognitoUserPoolId+"$", preSignupHandler)
+
+					require.Nil(t, err)
+					assert.False(t, route.Matches(testCase.event))
+				})
+			}
+		})
+
+		t.Run("Returns true for every trigger source of the family", func(t *testing.T) {
+			testCases := []struct {
+				route          routes.Route
+				triggerSources []string
+			}{
+				{
+					route: mustRoute(routes.NewCognitoPreSignupRoute(".*", nil)),
+					triggerSources: []string{
+						"PreSignUp_SignUp",
+						"PreSignUp_AdminCreateUser",
+						"PreSignUp_ExternalProvider",
+					},
+				},
+				{
+					route: mustRoute(routes.NewCognitoPostConfirmationRoute(".*", nil)),
+					triggerSources: []string{
+						"PostConfirmation_ConfirmSignUp",
+						"PostConfirmation_ConfirmForgotPassword",
+					},
+				},
+				{
+					route: mustRoute(routes.NewCognitoPreTokenGenRoute(".*", nil)),
+					triggerSources: []string{
+						"TokenGeneration_HostedAuth",
+						"TokenGeneration_Authentication",
+						"TokenGeneration_NewPasswordChallenge",
+						"TokenGeneration_AuthenticateDevice",
+						"TokenGeneration_RefreshTokens",
+					},
+				},
+				{
+					route: mustRoute(routes.NewCognitoCustomMessageRoute(".*", nil)),
+					triggerSources: []string{
+						"CustomMessage_SignUp",
+						"CustomMessage_AdminCreateUser",
+						"CustomMessage_ResendCode",
+						"CustomMessage_ForgotPassword",
+						"CustomMessage_UpdateUserAttribute",
+						"CustomMessage_VerifyUserAttribute",
+						"CustomMessage_Authentication",
+					},
+				},
+				{
+					route:          mustRoute(routes.NewCognitoDefineAuthChallengeRoute(".*", nil)),
+					triggerSources: []string{"DefineAuthChallenge_Authentication"},
+				},
+				{
+					route:          mustRoute(routes.NewCognitoCreateAuthChallengeRoute(".*", nil)),
+					triggerSources: []string{"CreateAuthChallenge_Authentication"},
+				},
+				{
+					route:          mustRoute(routes.NewCognitoVerifyAuthChallengeRoute(".*", nil)),
+					triggerSources: []string{"VerifyAuthChallengeResponse_Authentication"},
+				},
+				{
+					route: mustRoute(routes.NewCognitoMigrateUserRoute(".*", nil)),
+					triggerSources: []string{
+						"UserMigration_Authentication",
+						"UserMigration_ForgotPassword",
+					},
+				},
+			}
+
+			for _, testCase := range testCases {
+				for _, triggerSource := range testCase.triggerSources {
+					t.Run(triggerSource, func(t *testing.T) {
+						assert.True(t, testCase.route.Matches(map[string]interface{}{
+							"triggerSource": triggerSource,
+							"userPoolId":    cognitoUserPoolId,
+						}))
+					})
+				}
+			}
+		})
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Returns event modified by the handler", func(t *testing.T) {
+			requestCtx := context.TODO()
+
+			route, err := routes.NewCognitoPreSignupRoute(
+				".*",
+				func(
+					ctx context.Context,
+					request events.CognitoEventUserPoolsPreSignup,
+				) (events.CognitoEventUserPoolsPreSignup, error) {
+					assert.Equal(t, requestCtx, ctx)
+					assert.Equal(t, "PreSignUp_SignUp", request.TriggerSource)
+					assert.Equal(t, "user@example.com", request.Request.UserAttributes["email"])
+
+					request.Response.AutoConfirmUser = true
+
+					return request, nil
+				},
+			)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(requestCtx, map[string]interface{}{
+				"triggerSource": "PreSignUp_SignUp",
+				"userPoolId":    cognitoUserPoolId,
+				"request": map[string]interface{}{
+					"userAttributes": map[string]interface{}{
+						"email": "user@example.com",
+					},
+				},
+			})
+
+			assert.Nil(t, err)
+			require.IsType(t, events.CognitoEventUserPoolsPreSignup{}, resp)
+			assert.True(t, resp.(events.CognitoEventUserPoolsPreSignup).Response.AutoConfirmUser)
+			assert.Equal(t, cognitoUserPoolId, resp.(events.CognitoEventUserPoolsPreSignup).UserPoolID)
+		})
+
+		t.Run("Returns an error from the handler", func(t *testing.T) {
+			handlerErr := errors.New("error")
+
+			route, err := routes.NewCognitoMigrateUserRoute(
+				".*",
+				func(
+					ctx context.Context,
+					request events.CognitoEventUserPoolsMigrateUser,
+				) (events.CognitoEventUserPoolsMigrateUser, error) {
+					return request, handlerErr
+				},
+			)
+
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{
+				"triggerSource": "UserMigration_Authentication",
+				"userPoolId":    cognitoUserPoolId,
+			})
+
+			assert.Equal(t, handlerErr, err)
+		})
+
+		t.Run("Returns an error if event can not be unmarshalled", func(t *testing.T) {
+			route, err := routes.NewCognitoPreSignupRoute(".*", preSignupHandler)
+
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{
+				"triggerSource": "PreSignUp_SignUp",
+				"userPoolId":    cognitoUserPoolId,
+				"request":       "invalid",
+			})
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnmarshalError))
+		})
+	})
+}
+
+func mustRoute(route routes.Route, err error) routes.Route {
+	if err != nil {
+		panic(err)
+	}
+
+	return route
+}
@@ -0,0 +1,253 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	CorsAnyOrigin = "*"
+
+	OriginHeader                        = "Origin"
+	VaryHeader                          = "Vary"
+	AccessControlAllowOriginHeader      = "Access-Control-Allow-Origin"
+	AccessControlAllowMethodsHeader     = "Access-Control-Allow-Methods"
+	AccessControlAllowHeadersHeader     = "Access-Control-Allow-Headers"
+	AccessControlAllowCredentialsHeader = "Access-Control-Allow-Credentials"
+	AccessControlExposeHeadersHeader    = "Access-Control-Expose-Headers"
+	AccessControlMaxAgeHeader           = "Access-Control-Max-Age"
+	AccessControlRequestMethodHeader    = "Access-Control-Request-Method"
+	AccessControlRequestHeadersHeader   = "Access-Control-Request-Headers"
+
+	corsWildcard      = "*"
+	corsHeadersJoiner = ", "
+)
+
+// CorsPolicy adds CORS headers to the API Gateway responses and answers the preflight requests.
+type CorsPolicy struct {
+	origins        []corsOrigin
+	allowedHeaders []string
+	exposedHeaders []string
+	credentials    bool
+	maxAge         time.Duration
+}
+
+type corsOrigin struct {
+	prefix   string
+	suffix   string
+	wildcard bool
+}
+
+// NewCorsPolicy creates a policy allowing the origins, e.g. https://example.com.
+// Subdomains are allowed with a wildcard, e.g. https://*.example.com, and any origin with *.
+func NewCorsPolicy(origins ...string) (*CorsPolicy, error) {
+	policy := &CorsPolicy{origins: []corsOrigin{}}
+
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+
+		switch strings.Count(origin, corsWildcard) {
+		case 0:
+			policy.origins = append(policy.origins, corsOrigin{prefix: origin})
+		case 1:
+			i := strings.Index(origin, corsWildcard)
+			policy.origins = append(policy.origins, corsOrigin{prefix: origin[:i], suffix: origin[i+1:], wildcard: true})
+		default:
+			return nil, RouteCompileError.New("Invalid origin %s given, only one wildcard is allowed", origin)
+		}
+	}
+
+	return policy, nil
+}
+
+// NewCorsPolicyWithCredentials creates a policy allowing cookies and authorization headers from the origins,
+// the origin is echoed instead of *. Any origin can not be allowed with credentials, every site could make
+// credentialed requests otherwise.
+func NewCorsPolicyWithCredentials(origins ...string) (*CorsPolicy, error) {
+	policy, err := NewCorsPolicy(origins...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.allowsAnyOrigin() {
+		return nil, RouteCompileError.New("Any origin can not be allowed with credentials, the origins must be listed")
+	}
+
+	policy.credentials = true
+
+	return policy, nil
+}
+
+// WithAllowedHeaders sets the request headers allowed in the preflight response,
+// by default the headers requested by the browser are allowed.
+func (policy *CorsPolicy) WithAllowedHeaders(headers ...string) *CorsPolicy {
+	policy.allowedHeaders = headers
+
+	return policy
+}
+
+// WithExposedHeaders sets the response headers which can be read by the browser.
+func (policy *CorsPolicy) WithExposedHeaders(headers ...string) *CorsPolicy {
+	policy.exposedHeaders = headers
+
+	return policy
+}
+
+// WithMaxAge sets how long the browser can cache the preflight response.
+func (policy *CorsPolicy) WithMaxAge(maxAge time.Duration) *CorsPolicy {
+	policy.maxAge = maxAge
+
+	return policy
+}
+
+// AllowsOrigin checks if the origin is in the allowlist.
+func (policy *CorsPolicy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	origin = strings.ToLower(origin)
+
+	for _, allowed := range policy.origins {
+		if !allowed.wildcard && allowed.prefix == origin {
+			return true
+		}
+
+		if allowed.wildcard &&
+			len(origin) > len(allowed.prefix)+len(allowed.suffix) &&
+			strings.HasPrefix(origin, allowed.prefix) &&
+			strings.HasSuffix(origin, allowed.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCorsPreflight checks if the request is the CORS preflight request sent by the browser.
+func IsCorsPreflight(httpMethod string, headers map[string]string) bool {
+	return httpMethod == http.MethodOptions &&
+		HeaderValue(headers, OriginHeader) != "" &&
+		HeaderValue(headers, AccessControlRequestMethodHeader) != ""
+}
+
+// Decorate adds CORS headers to the response of the request with the given headers.
+// Vary: Origin is always added, so caches do not share responses between origins.
+func (policy *CorsPolicy) Decorate(
+	requestHeaders map[string]string,
+	response events.APIGatewayProxyResponse,
+) events.APIGatewayProxyResponse {
+	headers := map[string]string{}
+
+	for name, value := range response.Headers {
+		headers[name] = value
+	}
+
+	addVaryOrigin(headers)
+
+	origin := HeaderValue(requestHeaders, OriginHeader)
+
+	if policy.AllowsOrigin(origin) {
+		policy.addOriginHeaders(headers, origin)
+
+		if len(policy.exposedHeaders) > 0 {
+			headers[AccessControlExposeHeadersHeader] = strings.Join(policy.exposedHeaders, corsHeadersJoiner)
+		}
+	}
+
+	response.Headers = headers
+
+	return response
+}
+
+// Preflight creates the response to the preflight request for the path accepting the methods.
+// Requests from origins which are not allowed are responded with 403 Forbidden.
+func (policy *CorsPolicy) Preflight(requestHeaders map[string]string, methods []string) events.APIGatewayProxyResponse {
+	headers := map[string]string{}
+	addVaryOrigin(headers)
+
+	origin := HeaderValue(requestHeaders, OriginHeader)
+
+	if !policy.AllowsOrigin(origin) {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusForbidden, Headers: headers}
+	}
+
+	policy.addOriginHeaders(headers, origin)
+	headers[AccessControlAllowMethodsHeader] = strings.Join(addOptionsMethod(methods), corsHeadersJoiner)
+
+	allowedHeaders := strings.Join(policy.allowedHeaders, corsHeadersJoiner)
+
+	if len(policy.allowedHeaders) == 0 {
+		allowedHeaders = HeaderValue(requestHeaders, AccessControlRequestHeadersHeader)
+	}
+
+	if allowedHeaders != "" {
+		headers[AccessControlAllowHeadersHeader] = allowedHeaders
+	}
+
+	if policy.maxAge > 0 {
+		headers[AccessControlMaxAgeHeader] = strconv.Itoa(int(policy.maxAge / time.Second))
+	}
+
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent, Headers: headers}
+}
+
+func (policy *CorsPolicy) addOriginHeaders(headers map[string]string, origin string) {
+	headers[AccessControlAllowOriginHeader] = origin
+
+	if policy.credentials {
+		headers[AccessControlAllowCredentialsHeader] = "true"
+	} else if policy.allowsAnyOrigin() {
+		headers[AccessControlAllowOriginHeader] = CorsAnyOrigin
+	}
+}
+
+func (policy *CorsPolicy) allowsAnyOrigin() bool {
+	for _, allowed := range policy.origins {
+		if allowed.wildcard && allowed.prefix == "" && allowed.suffix == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func addVaryOrigin(headers map[string]string) {
+	for name, value := range headers {
+		if !strings.EqualFold(name, VaryHeader) {
+			continue
+		}
+
+		for _, varied := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(varied), OriginHeader) {
+				return
+			}
+		}
+
+		headers[name] = value + corsHeadersJoiner + OriginHeader
+
+		return
+	}
+
+	headers[VaryHeader] = OriginHeader
+}
+
+// HeaderValue finds the header ignoring the case of its name.
+func HeaderValue(headers map[string]string, name string) string {
+	if value, ok := headers[name]; ok {
+		return value
+	}
+
+	for headerName, value := range headers {
+		if strings.EqualFold(headerName, name) {
+			return value
+		}
+	}
+
+	return ""
+}
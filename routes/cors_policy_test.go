@@ -0,0 +1,181 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CorsPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewCorsPolicy", func(t *testing.T) {
+		t.Run("Returns an error if origin has more than one wildcard", func(t *testing.T) {
+			_, err := routes.NewCorsPolicy("https://*.*.example.com")
+
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("NewCorsPolicyWithCredentials", func(t *testing.T) {
+		t.Run("Returns an error if any origin is allowed", func(t *testing.T) {
+			_, err := routes.NewCorsPolicyWithCredentials("https://example.com", routes.CorsAnyOrigin)
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+		})
+
+		t.Run("Returns an error if origin has more than one wildcard", func(t *testing.T) {
+			_, err := routes.NewCorsPolicyWithCredentials("https://*.*.example.com")
+
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("AllowsOrigin", func(t *testing.T) {
+		policy, err := routes.NewCorsPolicy("https://example.com", "https://*.example.org")
+
+		assert.Nil(t, err)
+
+		testCases := map[string]bool{
+			"https://example.com":         true,
+			"https://EXAMPLE.com":         true,
+			"http://example.com":          false,
+			"https://app.example.org":     true,
+			"https://a.b.example.org":     true,
+			"https://example.org":         false,
+			"https://.example.org":        false,
+			"https://app.example.org.com": false,
+			"":                            false,
+		}
+
+		for origin, expected := range testCases {
+			assert.Equal(t, expected, policy.AllowsOrigin(origin), origin)
+		}
+	})
+
+	t.Run("Decorate", func(t *testing.T) {
+		t.Run("Adds headers for allowed origin", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicyWithCredentials("https://*.example.com")
+
+			assert.Nil(t, err)
+
+			response := policy.
+				WithExposedHeaders("X-Total-Count", "ETag").
+				Decorate(
+					map[string]string{"origin": "https://app.example.com"},
+					events.APIGatewayProxyResponse{
+						StatusCode: http.StatusOK,
+						Headers:    map[string]string{routes.ContentTypeHeader: routes.JsonContentType},
+					},
+				)
+
+			assert.Equal(t, map[string]string{
+				routes.ContentTypeHeader:                   routes.JsonContentType,
+				routes.VaryHeader:                          routes.OriginHeader,
+				routes.AccessControlAllowOriginHeader:      "https://app.example.com",
+				routes.AccessControlAllowCredentialsHeader: "true",
+				routes.AccessControlExposeHeadersHeader:    "X-Total-Count, ETag",
+			}, response.Headers)
+		})
+
+		t.Run("Adds only Vary header for not allowed origin", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			response := policy.Decorate(
+				map[string]string{"Origin": "https://evil.com"},
+				events.APIGatewayProxyResponse{Headers: map[string]string{"vary": "Accept-Encoding"}},
+			)
+
+			assert.Equal(t, map[string]string{"vary": "Accept-Encoding, Origin"}, response.Headers)
+		})
+
+		t.Run("Allows any origin with * without credentials", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicy(routes.CorsAnyOrigin)
+
+			assert.Nil(t, err)
+
+			response := policy.Decorate(
+				map[string]string{"Origin": "https://example.com"},
+				events.APIGatewayProxyResponse{},
+			)
+
+			assert.Equal(t, routes.CorsAnyOrigin, response.Headers[routes.AccessControlAllowOriginHeader])
+		})
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		t.Run("Responds with methods, requested headers and max age", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			response := policy.WithMaxAge(10*time.Minute).Preflight(
+				map[string]string{
+					"Origin":                         "https://example.com",
+					"Access-Control-Request-Method":  http.MethodPost,
+					"Access-Control-Request-Headers": "content-type, authorization",
+				},
+				[]string{http.MethodGet, http.MethodPost},
+			)
+
+			assert.Equal(t, events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNoContent,
+				Headers: map[string]string{
+					routes.VaryHeader:                      routes.OriginHeader,
+					routes.AccessControlAllowOriginHeader:  "https://example.com",
+					routes.AccessControlAllowMethodsHeader: "GET, POST, OPTIONS",
+					routes.AccessControlAllowHeadersHeader: "content-type, authorization",
+					routes.AccessControlMaxAgeHeader:       "600",
+				},
+			}, response)
+		})
+
+		t.Run("Responds with configured allowed headers", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			response := policy.WithAllowedHeaders("Content-Type").Preflight(
+				map[string]string{
+					"Origin":                         "https://example.com",
+					"Access-Control-Request-Headers": "x-custom",
+				},
+				[]string{http.MethodGet},
+			)
+
+			assert.Equal(t, "Content-Type", response.Headers[routes.AccessControlAllowHeadersHeader])
+		})
+
+		t.Run("Responds with 403 for not allowed origin", func(t *testing.T) {
+			policy, err := routes.NewCorsPolicy("https://example.com")
+
+			assert.Nil(t, err)
+
+			response := policy.Preflight(map[string]string{"Origin": "https://evil.com"}, []string{http.MethodGet})
+
+			assert.Equal(t, http.StatusForbidden, response.StatusCode)
+			assert.Empty(t, response.Headers[routes.AccessControlAllowOriginHeader])
+		})
+	})
+
+	t.Run("IsCorsPreflight", func(t *testing.T) {
+		assert.True(t, routes.IsCorsPreflight(http.MethodOptions, map[string]string{
+			"origin":                        "https://example.com",
+			"access-control-request-method": http.MethodGet,
+		}))
+		assert.False(t, routes.IsCorsPreflight(http.MethodOptions, map[string]string{"Origin": "https://example.com"}))
+		assert.False(t, routes.IsCorsPreflight(http.MethodGet, map[string]string{
+			"Origin":                        "https://example.com",
+			"Access-Control-Request-Method": http.MethodGet,
+		}))
+	})
+}
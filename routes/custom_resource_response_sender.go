@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/cfn"
+)
+
+const (
+	// CustomResourceResponseLimit is the maximum size of the response accepted by CloudFormation.
+	CustomResourceResponseLimit = 4096
+
+	customResourceSendTimeout = 10 * time.Second
+)
+
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// CustomResourceResponseSender uploads custom resource responses to the pre-signed ResponseURL.
+type CustomResourceResponseSender struct {
+	client HttpClient
+}
+
+// NewCustomResourceResponseSender uses the client or an http.Client with a 10 seconds timeout if it's nil.
+func NewCustomResourceResponseSender(client HttpClient) *CustomResourceResponseSender {
+	if client == nil {
+		client = &http.Client{Timeout: customResourceSendTimeout}
+	}
+
+	return &CustomResourceResponseSender{client: client}
+}
+
+// Send PUTs the response to the url. Responses over the CustomResourceResponseLimit are replaced with the FAILED
+// response without the data, so the stack does not wait for the response which will never arrive.
+func (sender *CustomResourceResponseSender) Send(ctx context.Context, url string, response *cfn.Response) error {
+	body, err := json.Marshal(response)
+
+	if err != nil {
+		return RouteMarshalError.Wrap(err, "Failed to marshal custom resource response to JSON")
+	}
+
+	if len(body) > CustomResourceResponseLimit {
+		limitedResponse := *response
+		limitedResponse.Status = cfn.StatusFailed
+		limitedResponse.Reason = "Response exceeds the 4096 bytes limit"
+		limitedResponse.Data = nil
+
+		body, err = json.Marshal(limitedResponse)
+
+		if err != nil {
+			return RouteMarshalError.Wrap(err, "Failed to marshal custom resource response to JSON")
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+
+	if err != nil {
+		return RouteResponseError.Wrap(err, "Failed to create custom resource response request")
+	}
+
+	// pre-signed url is signed without the content type
+	req = req.WithContext(ctx)
+	req.Header.Del("Content-Type")
+
+	resp, err := sender.client.Do(req)
+
+	if err != nil {
+		return RouteResponseError.Wrap(err, "Failed to send custom resource response")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return RouteResponseError.New("Custom resource response was rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
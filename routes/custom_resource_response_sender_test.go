@@ -0,0 +1,122 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CustomResourceResponseSender(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PUTs the response to the url", func(t *testing.T) {
+		server, requests := customResourceServer(t, http.StatusOK)
+		defer server.Close()
+
+		err := routes.NewCustomResourceResponseSender(nil).Send(context.TODO(), server.URL+"/response", &cfn.Response{
+			Status:             cfn.StatusSuccess,
+			RequestID:          "requestId",
+			LogicalResourceID:  "logicalResourceId",
+			StackID:            "stackId",
+			PhysicalResourceID: "physicalResourceId",
+			Data:               map[string]interface{}{"key": "value"},
+		})
+
+		require.Nil(t, err)
+
+		request := <-requests
+
+		assert.Equal(t, http.MethodPut, request.method)
+		assert.Equal(t, "/response", request.path)
+		assert.Empty(t, request.contentType)
+		assert.Equal(t, cfn.StatusSuccess, request.response.Status)
+		assert.Equal(t, "requestId", request.response.RequestID)
+		assert.Equal(t, "logicalResourceId", request.response.LogicalResourceID)
+		assert.Equal(t, "stackId", request.response.StackID)
+		assert.Equal(t, "physicalResourceId", request.response.PhysicalResourceID)
+		assert.Equal(t, map[string]interface{}{"key": "value"}, request.response.Data)
+	})
+
+	t.Run("Sends FAILED if response exceeds the limit", func(t *testing.T) {
+		server, requests := customResourceServer(t, http.StatusOK)
+		defer server.Close()
+
+		err := routes.NewCustomResourceResponseSender(server.Client()).Send(context.TODO(), server.URL, &cfn.Response{
+			Status:             cfn.StatusSuccess,
+			PhysicalResourceID: "physicalResourceId",
+			Data:               map[string]interface{}{"key": strings.Repeat("a", routes.CustomResourceResponseLimit)},
+		})
+
+		require.Nil(t, err)
+
+		request := <-requests
+
+		assert.Equal(t, cfn.StatusFailed, request.response.Status)
+		assert.Equal(t, "physicalResourceId", request.response.PhysicalResourceID)
+		assert.NotEmpty(t, request.response.Reason)
+		assert.Nil(t, request.response.Data)
+	})
+
+	t.Run("Returns an error if response was rejected", func(t *testing.T) {
+		server, _ := customResourceServer(t, http.StatusForbidden)
+		defer server.Close()
+
+		err := routes.NewCustomResourceResponseSender(nil).Send(context.TODO(), server.URL, &cfn.Response{})
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteResponseError))
+	})
+
+	t.Run("Returns an error if the context is done", func(t *testing.T) {
+		server, _ := customResourceServer(t, http.StatusOK)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		err := routes.NewCustomResourceResponseSender(nil).Send(ctx, server.URL, &cfn.Response{})
+
+		require.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteResponseError))
+	})
+}
+
+type customResourceRequest struct {
+	method      string
+	path        string
+	contentType string
+	response    cfn.Response
+}
+
+func customResourceServer(t *testing.T, statusCode int) (*httptest.Server, chan customResourceRequest) {
+	requests := make(chan customResourceRequest, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+
+		request := customResourceRequest{
+			method:      r.Method,
+			path:        r.URL.Path,
+			contentType: r.Header.Get("Content-Type"),
+		}
+
+		require.Nil(t, json.Unmarshal(body, &request.response))
+
+		requests <- request
+
+		w.WriteHeader(statusCode)
+	}))
+
+	return server, requests
+}
@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-lambda-go/cfn"
+)
+
+const (
+	// customResourceTimeoutMargin is left before the lambda deadline to send the FAILED response.
+	customResourceTimeoutMargin = 3 * time.Second
+)
+
+// CustomResourceRoute handles CloudFormation custom resources and sends the response to the ResponseURL.
+// FAILED is sent if the handler returns an error, panics or does not finish before the lambda times out,
+// so the stack never waits for the response which will never arrive. The context of the handler is done
+// when it times out, but the handler keeps running in the background until it returns.
+type CustomResourceRoute struct {
+	resourceType *regexp.Regexp
+	handler      CustomResourceHandler
+	sender       *CustomResourceResponseSender
+}
+
+type customResourceResult struct {
+	physicalResourceId string
+	data               map[string]interface{}
+	err                error
+}
+
+// NewCustomResourceRoute creates a route for the resource type, e.g. ^Custom::Xyz$.
+// A client with a 10 seconds timeout is used to send responses if the client is nil.
+func NewCustomResourceRoute(
+	resourceType string,
+	handler CustomResourceHandler,
+	client HttpClient,
+) (*CustomResourceRoute, error) {
+	compiledResourceType, err := regexp.Compile(resourceType)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &CustomResourceRoute{
+		resourceType: compiledResourceType,
+		handler:      handler,
+		sender:       NewCustomResourceResponseSender(client),
+	}, nil
+}
+
+func (route *CustomResourceRoute) Matches(event map[string]interface{}) bool {
+	requestType, _ := event["RequestType"].(string)
+
+	switch cfn.RequestType(requestType) {
+	case cfn.RequestCreate, cfn.RequestUpdate, cfn.RequestDelete:
+	default:
+		return false
+	}
+
+	if _, ok := event["ResponseURL"].(string); !ok {
+		return false
+	}
+
+	resourceType, ok := event["ResourceType"].(string)
+
+	if !ok || !route.resourceType.MatchString(resourceType) {
+		return false
+	}
+
+	return true
+}
+
+// Handle returns an error only if the response was not sent, as the handler errors are reported to the CloudFormation.
+func (route *CustomResourceRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	request := cfn.Event{}
+
+	if err := unmarshalEvent(event, &request); err != nil {
+		return nil, err
+	}
+
+	result := route.run(ctx, request)
+	response := cfn.NewResponse(&request)
+	response.PhysicalResourceID = result.physicalResourceId
+
+	if response.PhysicalResourceID == "" {
+		response.PhysicalResourceID = request.PhysicalResourceID
+	}
+
+	if response.PhysicalResourceID == "" {
+		response.PhysicalResourceID = request.RequestID
+	}
+
+	if result.err != nil {
+		response.Status = cfn.StatusFailed
+		response.Reason = result.err.Error()
+	} else {
+		response.Status = cfn.StatusSuccess
+		response.Data = result.data
+	}
+
+	// the response is sent even if the handler context is done, within the margin left before the lambda deadline
+	sendCtx, cancel := context.WithTimeout(context.Background(), customResourceTimeoutMargin)
+	defer cancel()
+
+	return nil, route.sender.Send(sendCtx, request.ResponseURL, response)
+}
+
+func (*CustomResourceRoute) HasResponse() bool {
+	return false
+}
+
+func (route *CustomResourceRoute) String() string {
+	return fmt.Sprintf("CloudFormation custom resource %s", route.resourceType.String())
+}
+
+func (route *CustomResourceRoute) run(ctx context.Context, request cfn.Event) customResourceResult {
+	results := make(chan customResourceResult, 1)
+	handlerCtx := ctx
+
+	var timeout <-chan struct{}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+
+		handlerCtx, cancel = context.WithDeadline(ctx, deadline.Add(-customResourceTimeoutMargin))
+		defer cancel()
+
+		timeout = handlerCtx.Done()
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				results <- customResourceResult{err: fmt.Errorf("Handler panicked: %v", r)}
+			}
+		}()
+
+		physicalResourceId, data, err := route.call(handlerCtx, request)
+
+		results <- customResourceResult{
+			physicalResourceId: physicalResourceId,
+			data:               data,
+			err:                err,
+		}
+	}()
+
+	select {
+	case result := <-results:
+		return result
+	case <-timeout:
+		return customResourceResult{err: errors.New("Handler timed out")}
+	}
+}
+
+func (route *CustomResourceRoute) call(
+	ctx context.Context,
+	request cfn.Event,
+) (string, map[string]interface{}, error) {
+	switch request.RequestType {
+	case cfn.RequestCreate:
+		return route.handler.Create(ctx, request)
+	case cfn.RequestUpdate:
+		return route.handler.Update(ctx, request)
+	default:
+		return route.handler.Delete(ctx, request)
+	}
+}
@@ -0,0 +1,248 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type customResourceHandlerFunc func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error)
+
+type customResourceHandler struct {
+	create customResourceHandlerFunc
+	update customResourceHandlerFunc
+	delete customResourceHandlerFunc
+}
+
+func (handler *customResourceHandler) Create(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+	return handler.create(ctx, request)
+}
+
+func (handler *customResourceHandler) Update(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+	return handler.update(ctx, request)
+}
+
+func (handler *customResourceHandler) Delete(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+	return handler.delete(ctx, request)
+}
+
+func Test_CustomResourceRoute(t *testing.T) {
+	t.Parallel()
+
+	nilHandler := &customResourceHandler{}
+
+	customResourceEvent := func(requestType string, responseUrl string) map[string]interface{} {
+		return map[string]interface{}{
+			"RequestType":        requestType,
+			"RequestId":          "requestId",
+			"ResponseURL":        responseUrl,
+			"ResourceType":       "Custom::Xyz",
+			"LogicalResourceId":  "logicalResourceId",
+			"PhysicalResourceId": "physicalResourceId",
+			"StackId":            "stackId",
+			"ResourceProperties": map[string]interface{}{"key": "value"},
+		}
+	}
+
+	t.Run("NewCustomResourceRoute returns an error if invalid regexp is passed", func(t *testing.T) {
+		_, err := routes.NewCustomResourceRoute("[invalid regexp", nilHandler, nil)
+
+		assert.Error(t, err)
+		assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+	})
+
+	t.Run("HasResponse returns false", func(t *testing.T) {
+		route, err := routes.NewCustomResourceRoute(".*", nilHandler, nil)
+
+		require.Nil(t, err)
+		assert.False(t, route.HasResponse())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			event       map[string]interface{}
+			expected    bool
+		}{
+			{
+				description: "Empty event",
+				event:       map[string]interface{}{},
+				expected:    false,
+			},
+			{
+				description: "Unknown request type",
+				event:       customResourceEvent("Unknown", "https://example.com"),
+				expected:    false,
+			},
+			{
+				description: "Resource type does not match",
+				event: map[string]interface{}{
+					"RequestType":  "Create",
+					"ResponseURL":  "https://example.com",
+					"ResourceType": "Custom::Another",
+				},
+				expected: false,
+			},
+			{
+				description: "Create",
+				event:       customResourceEvent("Create", "https://example.com"),
+				expected:    true,
+			},
+			{
+				description: "Update",
+				event:       customResourceEvent("Update", "https://example.com"),
+				expected:    true,
+			},
+			{
+				description: "Delete",
+				event:       customResourceEvent("Delete", "https://example.com"),
+				expected:    true,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				route, err := routes.NewCustomResourceRoute("^Custom::Xyz$", nilHandler, nil)
+
+				require.Nil(t, err)
+				assert.Equal(t, testCase.expected, route.Matches(testCase.event))
+			})
+		}
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Calls handler by the request type and sends SUCCESS", func(t *testing.T) {
+			handlerFor := func(name string) customResourceHandlerFunc {
+				return func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+					assert.Equal(t, "value", request.ResourceProperties["key"])
+
+					return name + "Id", map[string]interface{}{"called": name}, nil
+				}
+			}
+
+			handler := &customResourceHandler{
+				create: handlerFor("create"),
+				update: handlerFor("update"),
+				delete: handlerFor("delete"),
+			}
+
+			for _, requestType := range []string{"Create", "Update", "Delete"} {
+				t.Run(requestType, func(t *testing.T) {
+					server, requests := customResourceServer(t, http.StatusOK)
+					defer server.Close()
+
+					route, err := routes.NewCustomResourceRoute(".*", handler, server.Client())
+					require.Nil(t, err)
+
+					resp, err := route.Handle(context.TODO(), customResourceEvent(requestType, server.URL))
+
+					assert.Nil(t, err)
+					assert.Nil(t, resp)
+
+					request := <-requests
+					name := map[string]string{"Create": "create", "Update": "update", "Delete": "delete"}[requestType]
+
+					assert.Equal(t, cfn.StatusSuccess, request.response.Status)
+					assert.Equal(t, name+"Id", request.response.PhysicalResourceID)
+					assert.Equal(t, "requestId", request.response.RequestID)
+					assert.Equal(t, map[string]interface{}{"called": name}, request.response.Data)
+				})
+			}
+		})
+
+		t.Run("Sends FAILED", func(t *testing.T) {
+			testCases := []struct {
+				description string
+				ctx         func() (context.Context, context.CancelFunc)
+				handler     customResourceHandlerFunc
+				reason      string
+			}{
+				{
+					description: "If handler returns an error",
+					ctx:         func() (context.Context, context.CancelFunc) { return context.WithCancel(context.TODO()) },
+					handler: func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+						return "", nil, errors.New("error")
+					},
+					reason: "error",
+				},
+				{
+					description: "If handler panics",
+					ctx:         func() (context.Context, context.CancelFunc) { return context.WithCancel(context.TODO()) },
+					handler: func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+						panic("panic")
+					},
+					reason: "Handler panicked: panic",
+				},
+				{
+					description: "If handler times out",
+					ctx: func() (context.Context, context.CancelFunc) {
+						return context.WithTimeout(context.TODO(), 3*time.Second+time.Millisecond*50)
+					},
+					handler: func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+						<-ctx.Done()
+						time.Sleep(time.Millisecond * 100)
+
+						return "", nil, nil
+					},
+					reason: "Handler timed out",
+				},
+			}
+
+			for _, testCase := range testCases {
+				t.Run(testCase.description, func(t *testing.T) {
+					server, requests := customResourceServer(t, http.StatusOK)
+					defer server.Close()
+
+					route, err := routes.NewCustomResourceRoute(
+						".*",
+						&customResourceHandler{create: testCase.handler},
+						nil,
+					)
+					require.Nil(t, err)
+
+					ctx, cancel := testCase.ctx()
+					defer cancel()
+
+					_, err = route.Handle(ctx, customResourceEvent("Create", server.URL))
+
+					assert.Nil(t, err)
+
+					request := <-requests
+
+					assert.Equal(t, cfn.StatusFailed, request.response.Status)
+					assert.Equal(t, testCase.reason, request.response.Reason)
+					assert.Equal(t, "physicalResourceId", request.response.PhysicalResourceID)
+				})
+			}
+		})
+
+		t.Run("Returns an error if response was not sent", func(t *testing.T) {
+			server, _ := customResourceServer(t, http.StatusInternalServerError)
+			defer server.Close()
+
+			route, err := routes.NewCustomResourceRoute(
+				".*",
+				&customResourceHandler{
+					create: func(ctx context.Context, request cfn.Event) (string, map[string]interface{}, error) {
+						return "", nil, nil
+					},
+				},
+				nil,
+			)
+			require.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), customResourceEvent("Create", server.URL))
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteResponseError))
+		})
+	})
+}
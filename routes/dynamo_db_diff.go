@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DynamoDbDiff holds paths of the attributes which differ between the old and the new images,
+// nested paths are written as address.city and tags[0].
+type DynamoDbDiff struct {
+	Changed []string
+	Added   []string
+	Removed []string
+}
+
+// DiffDynamoDbRecord compares the OldImage and the NewImage of the record,
+// which requires the stream view type to be NEW_AND_OLD_IMAGES.
+func DiffDynamoDbRecord(record events.DynamoDBEventRecord) DynamoDbDiff {
+	return DiffDynamoDbImages(record.Change.OldImage, record.Change.NewImage)
+}
+
+// DiffDynamoDbImages compares the images attribute by attribute, descending into maps and lists.
+func DiffDynamoDbImages(oldImage, newImage map[string]events.DynamoDBAttributeValue) DynamoDbDiff {
+	diff := DynamoDbDiff{
+		Changed: []string{},
+		Added:   []string{},
+		Removed: []string{},
+	}
+
+	diff.compareMaps(oldImage, newImage, "")
+
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	return diff
+}
+
+// IsEmpty tells if the images are equal.
+func (diff DynamoDbDiff) IsEmpty() bool {
+	return len(diff.Changed) == 0 && len(diff.Added) == 0 && len(diff.Removed) == 0
+}
+
+// HasChanged tells if any of the attributes was changed, added or removed.
+// Change of a nested attribute is a change of its parent, e.g. address is changed if address.city is changed,
+// and replacement of the parent is a change of the nested attribute.
+func (diff DynamoDbDiff) HasChanged(paths ...string) bool {
+	for _, diffPaths := range [][]string{diff.Changed, diff.Added, diff.Removed} {
+		for _, diffPath := range diffPaths {
+			for _, path := range paths {
+				if isDynamoDbSubPath(diffPath, path) || isDynamoDbSubPath(path, diffPath) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (diff *DynamoDbDiff) compareMaps(oldMap, newMap map[string]events.DynamoDBAttributeValue, path string) {
+	for name, oldAttribute := range oldMap {
+		newAttribute, ok := newMap[name]
+
+		if !ok {
+			diff.Removed = append(diff.Removed, dynamoDbPath(path, name))
+
+			continue
+		}
+
+		diff.compare(oldAttribute, newAttribute, dynamoDbPath(path, name))
+	}
+
+	for name := range newMap {
+		if _, ok := oldMap[name]; !ok {
+			diff.Added = append(diff.Added, dynamoDbPath(path, name))
+		}
+	}
+}
+
+func (diff *DynamoDbDiff) compareLists(oldList, newList []events.DynamoDBAttributeValue, path string) {
+	for i := 0; i < len(oldList) || i < len(newList); i++ {
+		itemPath := path + "[" + strconv.Itoa(i) + "]"
+
+		switch {
+		case i >= len(newList):
+			diff.Removed = append(diff.Removed, itemPath)
+		case i >= len(oldList):
+			diff.Added = append(diff.Added, itemPath)
+		default:
+			diff.compare(oldList[i], newList[i], itemPath)
+		}
+	}
+}
+
+func (diff *DynamoDbDiff) compare(oldAttribute, newAttribute events.DynamoDBAttributeValue, path string) {
+	if oldAttribute.DataType() != newAttribute.DataType() {
+		diff.Changed = append(diff.Changed, path)
+
+		return
+	}
+
+	switch oldAttribute.DataType() {
+	case events.DataTypeMap:
+		diff.compareMaps(oldAttribute.Map(), newAttribute.Map(), path)
+	case events.DataTypeList:
+		diff.compareLists(oldAttribute.List(), newAttribute.List(), path)
+	default:
+		if !reflect.DeepEqual(dynamoDbComparable(oldAttribute), dynamoDbComparable(newAttribute)) {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+}
+
+// dynamoDbComparable converts the scalar or set attribute into a value which can be compared,
+// sets are unordered, so they are sorted. Numbers are compared exactly, as they have up to 38 digits.
+func dynamoDbComparable(attribute events.DynamoDBAttributeValue) interface{} {
+	switch attribute.DataType() {
+	case events.DataTypeNumber:
+		return normalizeDynamoDbNumber(attribute.Number())
+	case events.DataTypeStringSet:
+		return sortedStrings(attribute.StringSet())
+	case events.DataTypeNumberSet:
+		values := []string{}
+
+		for _, number := range attribute.NumberSet() {
+			values = append(values, normalizeDynamoDbNumber(number))
+		}
+
+		return sortedStrings(values)
+	case events.DataTypeBinarySet:
+		values := []string{}
+
+		for _, value := range attribute.BinarySet() {
+			values = append(values, string(value))
+		}
+
+		return sortedStrings(values)
+	}
+
+	return dynamoDbAttributeToInterface(attribute)
+}
+
+// normalizeDynamoDbNumber returns the same string for equal numbers, e.g. for 1, 1.0 and 10E-1.
+func normalizeDynamoDbNumber(number string) string {
+	value, ok := new(big.Rat).SetString(number)
+
+	if !ok {
+		return number
+	}
+
+	return value.RatString()
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func isDynamoDbSubPath(path string, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[")
+}
@@ -0,0 +1,105 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiffDynamoDbImages(t *testing.T) {
+	t.Parallel()
+
+	oldImage := map[string]events.DynamoDBAttributeValue{
+		"id":      events.NewStringAttribute("id"),
+		"status":  events.NewStringAttribute("pending"),
+		"count":   events.NewNumberAttribute("1"),
+		"tags":    events.NewStringSetAttribute([]string{"a", "b"}),
+		"removed": events.NewBooleanAttribute(true),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"city":   events.NewStringAttribute("Vilnius"),
+			"street": events.NewStringAttribute("Gedimino"),
+		}),
+		"items": events.NewListAttribute([]events.DynamoDBAttributeValue{
+			events.NewStringAttribute("first"),
+			events.NewStringAttribute("second"),
+		}),
+		"type": events.NewStringAttribute("1"),
+	}
+	newImage := map[string]events.DynamoDBAttributeValue{
+		"id":     events.NewStringAttribute("id"),
+		"status": events.NewStringAttribute("done"),
+		"count":  events.NewNumberAttribute("1.0"),
+		"tags":   events.NewStringSetAttribute([]string{"b", "a"}),
+		"added":  events.NewBooleanAttribute(true),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"city": events.NewStringAttribute("Kaunas"),
+			"zip":  events.NewStringAttribute("12345"),
+		}),
+		"items": events.NewListAttribute([]events.DynamoDBAttributeValue{
+			events.NewStringAttribute("first"),
+		}),
+		"type": events.NewNumberAttribute("1"),
+	}
+
+	diff := routes.DiffDynamoDbImages(oldImage, newImage)
+
+	t.Run("Computes changed, added and removed paths", func(t *testing.T) {
+		assert.Equal(t, routes.DynamoDbDiff{
+			Changed: []string{"address.city", "status", "type"},
+			Added:   []string{"added", "address.zip"},
+			Removed: []string{"address.street", "items[1]", "removed"},
+		}, diff)
+		assert.False(t, diff.IsEmpty())
+	})
+
+	t.Run("Equal images have an empty diff", func(t *testing.T) {
+		assert.True(t, routes.DiffDynamoDbImages(oldImage, oldImage).IsEmpty())
+	})
+
+	t.Run("Diff of the record compares old and new images", func(t *testing.T) {
+		assert.Equal(t, diff, routes.DiffDynamoDbRecord(events.DynamoDBEventRecord{
+			Change: events.DynamoDBStreamRecord{OldImage: oldImage, NewImage: newImage},
+		}))
+	})
+
+	t.Run("Compares numbers exactly", func(t *testing.T) {
+		diff := routes.DiffDynamoDbImages(
+			map[string]events.DynamoDBAttributeValue{
+				"id":      events.NewNumberAttribute("9007199254740993"),
+				"version": events.NewNumberAttribute("10E-1"),
+				"ids":     events.NewNumberSetAttribute([]string{"12345678901234567890123", "1"}),
+			},
+			map[string]events.DynamoDBAttributeValue{
+				"id":      events.NewNumberAttribute("9007199254740992"),
+				"version": events.NewNumberAttribute("1.00"),
+				"ids":     events.NewNumberSetAttribute([]string{"1.0", "12345678901234567890124"}),
+			},
+		)
+
+		assert.Equal(t, []string{"id", "ids"}, diff.Changed)
+	})
+
+	t.Run("HasChanged", func(t *testing.T) {
+		testCases := []struct {
+			paths    []string
+			expected bool
+		}{
+			{paths: []string{"status"}, expected: true},
+			{paths: []string{"id"}, expected: false},
+			{paths: []string{"count", "tags"}, expected: false},
+			{paths: []string{"address"}, expected: true},
+			{paths: []string{"address.city"}, expected: true},
+			{paths: []string{"items"}, expected: true},
+			{paths: []string{"items[0]"}, expected: false},
+			{paths: []string{"removed.nested"}, expected: true},
+			{paths: []string{"stat"}, expected: false},
+			{paths: []string{"id", "added"}, expected: true},
+		}
+
+		for _, testCase := range testCases {
+			assert.Equal(t, testCase.expected, diff.HasChanged(testCase.paths...), "%v", testCase.paths)
+		}
+	})
+}
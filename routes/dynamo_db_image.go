@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// UnmarshalDynamoDbNewImage decodes the NewImage of the record into the out, see UnmarshalDynamoDbImage.
+func UnmarshalDynamoDbNewImage(record events.DynamoDBEventRecord, out interface{}) error {
+	return UnmarshalDynamoDbImage(record.Change.NewImage, out)
+}
+
+// UnmarshalDynamoDbOldImage decodes the OldImage of the record into the out, see UnmarshalDynamoDbImage.
+func UnmarshalDynamoDbOldImage(record events.DynamoDBEventRecord, out interface{}) error {
+	return UnmarshalDynamoDbImage(record.Change.OldImage, out)
+}
+
+// UnmarshalDynamoDbImage decodes the stream image into the struct or map pointed by out
+// with dynamodbattribute.UnmarshalMap of the AWS SDK, so the dynamodbav tags and their options work the same way.
+func UnmarshalDynamoDbImage(image map[string]events.DynamoDBAttributeValue, out interface{}) error {
+	if image == nil {
+		value := reflect.ValueOf(out)
+
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			return RouteUnmarshalError.New("Expected non nil pointer, got %T", out)
+		}
+
+		return nil
+	}
+
+	if err := dynamodbattribute.UnmarshalMap(dynamoDbAttributeValues(image), out); err != nil {
+		return RouteUnmarshalError.Wrap(err, "Failed to decode DynamoDB image")
+	}
+
+	return nil
+}
+
+// dynamoDbAttributeValues converts the image of the Lambda event into the attribute values of the AWS SDK.
+func dynamoDbAttributeValues(image map[string]events.DynamoDBAttributeValue) map[string]*dynamodb.AttributeValue {
+	values := make(map[string]*dynamodb.AttributeValue, len(image))
+
+	for name, attribute := range image {
+		values[name] = dynamoDbAttributeValue(attribute)
+	}
+
+	return values
+}
+
+func dynamoDbAttributeValue(attribute events.DynamoDBAttributeValue) *dynamodb.AttributeValue {
+	value := &dynamodb.AttributeValue{}
+
+	switch attribute.DataType() {
+	case events.DataTypeString:
+		value.SetS(attribute.String())
+	case events.DataTypeNumber:
+		value.SetN(attribute.Number())
+	case events.DataTypeBoolean:
+		value.SetBOOL(attribute.Boolean())
+	case events.DataTypeBinary:
+		value.SetB(attribute.Binary())
+	case events.DataTypeStringSet:
+		value.SetSS(aws.StringSlice(attribute.StringSet()))
+	case events.DataTypeNumberSet:
+		value.SetNS(aws.StringSlice(attribute.NumberSet()))
+	case events.DataTypeBinarySet:
+		value.SetBS(attribute.BinarySet())
+	case events.DataTypeList:
+		list := make([]*dynamodb.AttributeValue, len(attribute.List()))
+
+		for i, item := range attribute.List() {
+			list[i] = dynamoDbAttributeValue(item)
+		}
+
+		value.SetL(list)
+	case events.DataTypeMap:
+		value.SetM(dynamoDbAttributeValues(attribute.Map()))
+	default:
+		value.SetNULL(true)
+	}
+
+	return value
+}
+
+func dynamoDbAttributeToInterface(attribute events.DynamoDBAttributeValue) interface{} {
+	switch attribute.DataType() {
+	case events.DataTypeString:
+		return attribute.String()
+	case events.DataTypeNumber:
+		value, _ := attribute.Float()
+
+		return value
+	case events.DataTypeBoolean:
+		return attribute.Boolean()
+	case events.DataTypeBinary:
+		return attribute.Binary()
+	case events.DataTypeStringSet:
+		return attribute.StringSet()
+	case events.DataTypeNumberSet:
+		values := []float64{}
+
+		for _, number := range attribute.NumberSet() {
+			value, _ := strconv.ParseFloat(number, 64)
+			values = append(values, value)
+		}
+
+		return values
+	case events.DataTypeBinarySet:
+		return attribute.BinarySet()
+	case events.DataTypeList:
+		values := []interface{}{}
+
+		for _, value := range attribute.List() {
+			values = append(values, dynamoDbAttributeToInterface(value))
+		}
+
+		return values
+	case events.DataTypeMap:
+		values := map[string]interface{}{}
+
+		for key, value := range attribute.Map() {
+			values[key] = dynamoDbAttributeToInterface(value)
+		}
+
+		return values
+	}
+
+	return nil
+}
+
+func dynamoDbPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
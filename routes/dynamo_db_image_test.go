@@ -0,0 +1,154 @@
+package routes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dynamoDbImageBase struct {
+	Id string `dynamodbav:"id"`
+}
+
+type dynamoDbImageAddress struct {
+	City string `dynamodbav:"city"`
+}
+
+type dynamoDbImage struct {
+	dynamoDbImageBase
+	Name      string                 `dynamodbav:"name"`
+	Age       int                    `dynamodbav:"age"`
+	Score     float64                `dynamodbav:"score"`
+	Active    bool                   `dynamodbav:"active,omitempty"`
+	Tags      []string               `dynamodbav:"tags,stringset"`
+	Numbers   []int                  `dynamodbav:"numbers"`
+	Address   *dynamoDbImageAddress  `dynamodbav:"address"`
+	Extra     map[string]interface{} `dynamodbav:"extra"`
+	ExpiresAt time.Time              `dynamodbav:"expiresAt,unixtime"`
+	CreatedAt time.Time              `dynamodbav:"createdAt"`
+	Ignored   string                 `dynamodbav:"-"`
+	Untagged  string
+	Missing   string `dynamodbav:"missing"`
+}
+
+type DynamoDbImageKey struct {
+	Id string `dynamodbav:"id"`
+}
+
+type dynamoDbImageOptions struct {
+	*DynamoDbImageKey
+	Count int `dynamodbav:"count,string"`
+}
+
+func Test_UnmarshalDynamoDbImage(t *testing.T) {
+	t.Parallel()
+
+	image := map[string]events.DynamoDBAttributeValue{
+		"id":      events.NewStringAttribute("id"),
+		"name":    events.NewStringAttribute("name"),
+		"age":     events.NewNumberAttribute("42"),
+		"score":   events.NewNumberAttribute("1.5"),
+		"active":  events.NewBooleanAttribute(true),
+		"tags":    events.NewStringSetAttribute([]string{"a", "b"}),
+		"numbers": events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewNumberAttribute("1")}),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"city": events.NewStringAttribute("Vilnius"),
+		}),
+		"extra": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"count": events.NewNumberAttribute("2"),
+			"null":  events.NewNullAttribute(),
+		}),
+		"expiresAt": events.NewNumberAttribute("1600000000"),
+		"createdAt": events.NewStringAttribute("2020-09-13T12:26:40Z"),
+		"Ignored":   events.NewStringAttribute("ignored"),
+		"-":         events.NewStringAttribute("ignored"),
+		"Untagged":  events.NewStringAttribute("untagged"),
+	}
+
+	t.Run("Decodes the image into the struct", func(t *testing.T) {
+		out := dynamoDbImage{}
+
+		require.Nil(t, routes.UnmarshalDynamoDbImage(image, &out))
+
+		assert.Equal(t, dynamoDbImage{
+			dynamoDbImageBase: dynamoDbImageBase{Id: "id"},
+			Name:              "name",
+			Age:               42,
+			Score:             1.5,
+			Active:            true,
+			Tags:              []string{"a", "b"},
+			Numbers:           []int{1},
+			Address:           &dynamoDbImageAddress{City: "Vilnius"},
+			Extra:             map[string]interface{}{"count": float64(2), "null": nil},
+			ExpiresAt:         time.Unix(1600000000, 0),
+			CreatedAt:         time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC),
+			Untagged:          "untagged",
+		}, out)
+	})
+
+	t.Run("Supports the tag options and embedded pointers of the AWS SDK", func(t *testing.T) {
+		out := dynamoDbImageOptions{}
+
+		require.Nil(t, routes.UnmarshalDynamoDbImage(map[string]events.DynamoDBAttributeValue{
+			"id":    events.NewStringAttribute("id"),
+			"count": events.NewStringAttribute("5"),
+		}, &out))
+
+		assert.Equal(t, dynamoDbImageOptions{DynamoDbImageKey: &DynamoDbImageKey{Id: "id"}, Count: 5}, out)
+	})
+
+	t.Run("Decodes new and old images of the record", func(t *testing.T) {
+		record := events.DynamoDBEventRecord{
+			Change: events.DynamoDBStreamRecord{
+				NewImage: map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("new")},
+				OldImage: map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute("old")},
+			},
+		}
+		newImage := dynamoDbImageBase{}
+		oldImage := dynamoDbImageBase{}
+
+		require.Nil(t, routes.UnmarshalDynamoDbNewImage(record, &newImage))
+		require.Nil(t, routes.UnmarshalDynamoDbOldImage(record, &oldImage))
+
+		assert.Equal(t, "new", newImage.Id)
+		assert.Equal(t, "old", oldImage.Id)
+	})
+
+	t.Run("Returns an error", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			image       map[string]events.DynamoDBAttributeValue
+			out         interface{}
+		}{
+			{
+				description: "If out is not a pointer",
+				image:       image,
+				out:         dynamoDbImage{},
+			},
+			{
+				description: "If attribute type does not match the field",
+				image:       map[string]events.DynamoDBAttributeValue{"age": events.NewStringAttribute("42")},
+				out:         &dynamoDbImage{},
+			},
+			{
+				description: "If number overflows the field",
+				image:       map[string]events.DynamoDBAttributeValue{"value": events.NewNumberAttribute("300")},
+				out:         &map[string]int8{},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				err := routes.UnmarshalDynamoDbImage(testCase.image, testCase.out)
+
+				require.Error(t, err)
+				assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnmarshalError))
+			})
+		}
+	})
+}
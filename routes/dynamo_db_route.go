@@ -9,14 +9,48 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 )
 
+const (
+	DynamoDbEventNameInsert = "INSERT"
+	DynamoDbEventNameModify = "MODIFY"
+	DynamoDbEventNameRemove = "REMOVE"
+
+	dynamoDbTtlUserIdentityType        = "Service"
+	dynamoDbTtlUserIdentityPrincipalId = "dynamodb.amazonaws.com"
+)
+
+type dynamoDbTtlFilter int
+
+const (
+	dynamoDbTtlAny dynamoDbTtlFilter = iota
+	dynamoDbTtlExclude
+	dynamoDbTtlOnly
+)
+
+// DynamoDbRoute passes DynamoDB stream events to the handler.
+// Routes created with NewDynamoDbRecordRoute or NewDynamoDbBatchRoute report batch item failures,
+// which requires ReportBatchItemFailures to be enabled on the event source mapping.
 type DynamoDbRoute struct {
-	eventSourceArn *regexp.Regexp
-	handler        DynamoDbHandlerFunc
+	eventSourceArn    *regexp.Regexp
+	handler           DynamoDbErrorHandlerFunc
+	batchHandler      DynamoDbBatchHandlerFunc
+	eventNames        []string
+	ttlFilter         dynamoDbTtlFilter
+	changedAttributes []string
+	filterCriteria    *FilterCriteria
 }
 
+// NewDynamoDbRoute creates a route for the handler which fails by panicking,
+// prefer NewDynamoDbErrorRoute for the new handlers.
 func NewDynamoDbRoute(
 	eventSourceArn string,
 	handler DynamoDbHandlerFunc,
+) (*DynamoDbRoute, error) {
+	return NewDynamoDbErrorRoute(eventSourceArn, AdaptDynamoDbHandler(handler))
+}
+
+func NewDynamoDbErrorRoute(
+	eventSourceArn string,
+	handler DynamoDbErrorHandlerFunc,
 ) (*DynamoDbRoute, error) {
 	compiledEventSourceArn, err := regexp.Compile(eventSourceArn)
 
@@ -30,6 +64,97 @@ func NewDynamoDbRoute(
 	}, nil
 }
 
+// NewDynamoDbBatchRoute creates a route which handler returns sequence numbers of the failed records.
+func NewDynamoDbBatchRoute(
+	eventSourceArn string,
+	handler DynamoDbBatchHandlerFunc,
+) (*DynamoDbRoute, error) {
+	compiledEventSourceArn, err := regexp.Compile(eventSourceArn)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &DynamoDbRoute{
+		eventSourceArn: compiledEventSourceArn,
+		batchHandler:   handler,
+	}, nil
+}
+
+// NewDynamoDbRecordRoute creates a route which calls the handler for every record.
+// Processing stops at the first failed record to preserve the shard order,
+// the failed record and all records after it are retried.
+func NewDynamoDbRecordRoute(
+	eventSourceArn string,
+	handler DynamoDbRecordHandlerFunc,
+) (*DynamoDbRoute, error) {
+	return NewDynamoDbBatchRoute(eventSourceArn, dynamoDbRecordsBatchHandler(handler))
+}
+
+// WithEventNames limits records passed to the handler by the event name, e.g. DynamoDbEventNameInsert.
+// Records which do not pass the filters are skipped and considered as processed,
+// the handler is not called if all records are skipped.
+func (route *DynamoDbRoute) WithEventNames(eventNames ...string) *DynamoDbRoute {
+	route.eventNames = eventNames
+
+	return route
+}
+
+// WithoutTtlDeletions skips records of the items deleted by the Time to Live process.
+func (route *DynamoDbRoute) WithoutTtlDeletions() *DynamoDbRoute {
+	route.ttlFilter = dynamoDbTtlExclude
+
+	return route
+}
+
+// OnlyTtlDeletions passes only records of the items deleted by the Time to Live process.
+func (route *DynamoDbRoute) OnlyTtlDeletions() *DynamoDbRoute {
+	route.ttlFilter = dynamoDbTtlOnly
+
+	return route
+}
+
+// WithChangedAttributes passes only records which change, add or remove any of the attributes,
+// e.g. "status" or "address.city", see DynamoDbDiff.HasChanged.
+// Requires the stream view type to be NEW_AND_OLD_IMAGES, combine with WithEventNames(DynamoDbEventNameModify)
+// to skip inserts and removals.
+func (route *DynamoDbRoute) WithChangedAttributes(paths ...string) *DynamoDbRoute {
+	route.changedAttributes = paths
+
+	return route
+}
+
+// WithFilterCriteria passes only records which match the criteria, e.g. created with
+// NewFilterCriteria(`{"dynamodb": {"NewImage": {"status": {"S": ["done"]}}}}`).
+func (route *DynamoDbRoute) WithFilterCriteria(criteria *FilterCriteria) *DynamoDbRoute {
+	route.filterCriteria = criteria
+
+	return route
+}
+
+// IsDynamoDbTtlDeletion tells if the record was created by the Time to Live process rather than a user deletion.
+func IsDynamoDbTtlDeletion(record events.DynamoDBEventRecord) bool {
+	return record.EventName == DynamoDbEventNameRemove &&
+		record.UserIdentity != nil &&
+		record.UserIdentity.Type == dynamoDbTtlUserIdentityType &&
+		record.UserIdentity.PrincipalID == dynamoDbTtlUserIdentityPrincipalId
+}
+
+// AdaptDynamoDbHandler converts the handler which fails by panicking into the error returning handler.
+func AdaptDynamoDbHandler(handler DynamoDbHandlerFunc) DynamoDbErrorHandlerFunc {
+	return func(ctx context.Context, request events.DynamoDBEvent) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = RouteHandlerPanicError.New("Handler panicked: %v", r)
+			}
+		}()
+
+		handler(ctx, request)
+
+		return nil
+	}
+}
+
 func (route *DynamoDbRoute) Matches(event map[string]interface{}) bool {
 	if event["Records"] == nil {
 		return false
@@ -64,7 +189,7 @@ func (route *DynamoDbRoute) Matches(event map[string]interface{}) bool {
 			return false
 		}
 
-		return true
+		break
 	}
 
 	return true
@@ -85,15 +210,97 @@ func (route *DynamoDbRoute) Handle(ctx context.Context, event map[string]interfa
 		return nil, RouteUnmarshalError.Wrap(err, "Failed to unmarshal request from the JSON")
 	}
 
-	route.handler(ctx, request)
+	if route.hasFilters() {
+		request.Records = route.filterRecords(request.Records)
+
+		if len(request.Records) == 0 {
+			return route.emptyResponse(), nil
+		}
+	}
+
+	if route.batchHandler != nil {
+		return route.batchHandler(ctx, request)
+	}
 
-	return nil, nil
+	return nil, route.handler(ctx, request)
 }
 
-func (*DynamoDbRoute) HasResponse() bool {
-	return false
+func (route *DynamoDbRoute) HasResponse() bool {
+	return route.batchHandler != nil
 }
 
 func (route *DynamoDbRoute) String() string {
 	return fmt.Sprintf("Dynamo db event for %s", route.eventSourceArn.String())
 }
+
+func (route *DynamoDbRoute) hasFilters() bool {
+	return len(route.eventNames) > 0 ||
+		route.ttlFilter != dynamoDbTtlAny ||
+		len(route.changedAttributes) > 0 ||
+		route.filterCriteria != nil
+}
+
+func (route *DynamoDbRoute) matchesRecord(record events.DynamoDBEventRecord) bool {
+	if len(route.eventNames) > 0 && !hasString(route.eventNames, record.EventName) {
+		return false
+	}
+
+	switch route.ttlFilter {
+	case dynamoDbTtlExclude:
+		if IsDynamoDbTtlDeletion(record) {
+			return false
+		}
+	case dynamoDbTtlOnly:
+		if !IsDynamoDbTtlDeletion(record) {
+			return false
+		}
+	}
+
+	if len(route.changedAttributes) > 0 && !DiffDynamoDbRecord(record).HasChanged(route.changedAttributes...) {
+		return false
+	}
+
+	if route.filterCriteria != nil && !route.filterCriteria.Matches(record) {
+		return false
+	}
+
+	return true
+}
+
+func (route *DynamoDbRoute) filterRecords(records []events.DynamoDBEventRecord) []events.DynamoDBEventRecord {
+	filtered := []events.DynamoDBEventRecord{}
+
+	for _, record := range records {
+		if route.matchesRecord(record) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return filtered
+}
+
+func (route *DynamoDbRoute) emptyResponse() interface{} {
+	if route.batchHandler != nil {
+		return events.DynamoDBEventResponse{BatchItemFailures: []events.DynamoDBBatchItemFailure{}}
+	}
+
+	return nil
+}
+
+func dynamoDbRecordsBatchHandler(handler DynamoDbRecordHandlerFunc) DynamoDbBatchHandlerFunc {
+	return func(ctx context.Context, request events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+		response := events.DynamoDBEventResponse{BatchItemFailures: []events.DynamoDBBatchItemFailure{}}
+
+		for _, record := range request.Records {
+			if err := handler(ctx, record); err != nil {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.DynamoDBBatchItemFailure{
+					ItemIdentifier: record.Change.SequenceNumber,
+				})
+
+				break
+			}
+		}
+
+		return response, nil
+	}
+}
@@ -3,6 +3,7 @@ package routes_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/joomcode/errorx"
@@ -17,6 +18,9 @@ func Test_DynamoDbRoute(t *testing.T) {
 	t.Parallel()
 
 	voidHandler := func(ctx context.Context, request events.DynamoDBEvent) {}
+	nilRecordHandler := func(ctx context.Context, record events.DynamoDBEventRecord) error {
+		return nil
+	}
 
 	t.Run("NewDynamoDbRoute", func(t *testing.T) {
 		t.Run("Returns an error if invalid regexp is passed", func(t *testing.T) {
@@ -24,6 +28,11 @@ func Test_DynamoDbRoute(t *testing.T) {
 
 			assert.Error(t, err)
 			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+
+			_, err = routes.NewDynamoDbRecordRoute("[invalid regexp", nilRecordHandler)
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
 		})
 	})
 
@@ -34,6 +43,13 @@ func Test_DynamoDbRoute(t *testing.T) {
 		assert.False(t, router.HasResponse())
 	})
 
+	t.Run("HasResponse returns true if route reports batch item failures", func(t *testing.T) {
+		router, err := routes.NewDynamoDbRecordRoute(".*", nilRecordHandler)
+
+		assert.Nil(t, err)
+		assert.True(t, router.HasResponse())
+	})
+
 	t.Run("Matches", func(t *testing.T) {
 		t.Run("Returns false", func(t *testing.T) {
 			t.Run("If Records key is not set", func(t *testing.T) {
@@ -144,5 +160,313 @@ func Test_DynamoDbRoute(t *testing.T) {
 
 			route.Handle(requestContext, event)
 		})
+
+		t.Run("Returns an error if panic based handler panics", func(t *testing.T) {
+			route, err := routes.NewDynamoDbRoute(
+				".*",
+				func(ctx context.Context, request events.DynamoDBEvent) {
+					panic("Failed to consume event")
+				},
+			)
+
+			assert.Nil(t, err)
+
+			_, err = route.Handle(context.TODO(), map[string]interface{}{})
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteHandlerPanicError))
+		})
+
+		t.Run("Returns an error from the error handler", func(t *testing.T) {
+			handlerErr := errors.New("error")
+
+			route, err := routes.NewDynamoDbErrorRoute(
+				".*",
+				func(ctx context.Context, request events.DynamoDBEvent) error {
+					return handlerErr
+				},
+			)
+
+			assert.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, handlerErr, err)
+		})
+
+		t.Run("Returns batch item failures from the batch handler", func(t *testing.T) {
+			response := events.DynamoDBEventResponse{
+				BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "1"}},
+			}
+
+			route, err := routes.NewDynamoDbBatchRoute(
+				".*",
+				func(ctx context.Context, request events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+					return response, nil
+				},
+			)
+
+			assert.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{})
+
+			assert.Nil(t, err)
+			assert.Equal(t, response, resp)
+		})
+
+		t.Run("Stops at the first record failed in the record handler", func(t *testing.T) {
+			handled := []string{}
+
+			route, err := routes.NewDynamoDbRecordRoute(
+				".*",
+				func(ctx context.Context, record events.DynamoDBEventRecord) error {
+					handled = append(handled, record.Change.SequenceNumber)
+
+					if record.Change.SequenceNumber == "2" {
+						return errors.New("error")
+					}
+
+					return nil
+				},
+			)
+
+			assert.Nil(t, err)
+
+			records := []map[string]interface{}{}
+
+			for _, sequenceNumber := range []string{"1", "2", "3"} {
+				records = append(records, map[string]interface{}{
+					"dynamodb": map[string]interface{}{"SequenceNumber": sequenceNumber},
+				})
+			}
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{"Records": records})
+
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"1", "2"}, handled)
+			assert.Equal(t, events.DynamoDBEventResponse{
+				BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "2"}},
+			}, resp)
+		})
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		dynamoDbRecord := func(eventName string, userIdentity map[string]interface{}) interface{} {
+			record := map[string]interface{}{
+				"eventName":      eventName,
+				"eventSourceARN": "arn",
+				"dynamodb": map[string]interface{}{
+					"SequenceNumber": eventName,
+				},
+			}
+
+			if userIdentity != nil {
+				record["userIdentity"] = userIdentity
+			}
+
+			return record
+		}
+		ttlIdentity := map[string]interface{}{
+			"type":        "Service",
+			"principalId": "dynamodb.amazonaws.com",
+		}
+
+		testCases := []struct {
+			description string
+			route       func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute
+			records     []interface{}
+			handled     []string
+		}{
+			{
+				description: "Matches an event name",
+				route: func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute {
+					return route.WithEventNames(routes.DynamoDbEventNameInsert, routes.DynamoDbEventNameModify)
+				},
+				records: []interface{}{
+					dynamoDbRecord("INSERT", nil),
+					dynamoDbRecord("REMOVE", nil),
+					dynamoDbRecord("MODIFY", nil),
+				},
+				handled: []string{"INSERT", "MODIFY"},
+			},
+			{
+				description: "Skips the batch if no record has the event name",
+				route: func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute {
+					return route.WithEventNames(routes.DynamoDbEventNameInsert)
+				},
+				records: []interface{}{dynamoDbRecord("REMOVE", nil)},
+				handled: []string{},
+			},
+			{
+				description: "Skips TTL deletions",
+				route: func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute {
+					return route.WithoutTtlDeletions()
+				},
+				records: []interface{}{
+					dynamoDbRecord("REMOVE", ttlIdentity),
+					dynamoDbRecord("MODIFY", nil),
+				},
+				handled: []string{"MODIFY"},
+			},
+			{
+				description: "Passes only TTL deletions",
+				route: func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute {
+					return route.OnlyTtlDeletions()
+				},
+				records: []interface{}{
+					dynamoDbRecord("REMOVE", map[string]interface{}{"type": "Service", "principalId": "another"}),
+					dynamoDbRecord("REMOVE", ttlIdentity),
+				},
+				handled: []string{"REMOVE"},
+			},
+			{
+				description: "Skips user deletions if only TTL deletions are expected",
+				route: func(route *routes.DynamoDbRoute) *routes.DynamoDbRoute {
+					return route.OnlyTtlDeletions()
+				},
+				records: []interface{}{dynamoDbRecord("REMOVE", nil)},
+				handled: []string{},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				handled := []string{}
+
+				route, err := routes.NewDynamoDbRecordRoute(
+					".*",
+					func(ctx context.Context, record events.DynamoDBEventRecord) error {
+						handled = append(handled, record.Change.SequenceNumber)
+
+						return nil
+					},
+				)
+
+				assert.Nil(t, err)
+
+				route = testCase.route(route)
+				event := map[string]interface{}{"Records": testCase.records}
+
+				assert.True(t, route.Matches(event))
+
+				resp, err := route.Handle(context.TODO(), event)
+
+				assert.Nil(t, err)
+				assert.Equal(t, testCase.handled, handled)
+				assert.Equal(t, events.DynamoDBEventResponse{
+					BatchItemFailures: []events.DynamoDBBatchItemFailure{},
+				}, resp)
+			})
+		}
+	})
+
+	t.Run("Passes only records which change the attributes", func(t *testing.T) {
+		handled := []string{}
+
+		route, err := routes.NewDynamoDbRecordRoute(
+			".*",
+			func(ctx context.Context, record events.DynamoDBEventRecord) error {
+				handled = append(handled, record.Change.SequenceNumber)
+
+				return nil
+			},
+		)
+
+		assert.Nil(t, err)
+
+		route = route.WithChangedAttributes("status")
+		modifyRecord := func(sequenceNumber string, oldStatus string, newStatus string) map[string]interface{} {
+			return map[string]interface{}{
+				"eventName":      "MODIFY",
+				"eventSourceARN": "arn",
+				"dynamodb": map[string]interface{}{
+					"SequenceNumber": sequenceNumber,
+					"OldImage":       map[string]interface{}{"status": map[string]interface{}{"S": oldStatus}},
+					"NewImage":       map[string]interface{}{"status": map[string]interface{}{"S": newStatus}},
+				},
+			}
+		}
+
+		unchanged := map[string]interface{}{
+			"Records": []interface{}{modifyRecord("1", "pending", "pending")},
+		}
+
+		assert.True(t, route.Matches(unchanged))
+
+		resp, err := route.Handle(context.TODO(), unchanged)
+
+		assert.Nil(t, err)
+		assert.Empty(t, handled)
+		assert.Equal(t, events.DynamoDBEventResponse{BatchItemFailures: []events.DynamoDBBatchItemFailure{}}, resp)
+
+		event := map[string]interface{}{
+			"Records": []interface{}{
+				modifyRecord("1", "pending", "pending"),
+				modifyRecord("2", "pending", "done"),
+			},
+		}
+
+		assert.True(t, route.Matches(event))
+
+		_, err = route.Handle(context.TODO(), event)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2"}, handled)
+	})
+
+	t.Run("Passes only records which match the filter criteria", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(`{"dynamodb": {"NewImage": {"status": {"S": ["done"]}}}}`)
+
+		assert.Nil(t, err)
+
+		called := false
+		route, err := routes.NewDynamoDbErrorRoute(".*", func(ctx context.Context, request events.DynamoDBEvent) error {
+			called = true
+
+			return nil
+		})
+
+		assert.Nil(t, err)
+
+		route = route.WithFilterCriteria(criteria)
+		event := map[string]interface{}{
+			"Records": []interface{}{
+				map[string]interface{}{
+					"eventSourceARN": "arn",
+					"dynamodb": map[string]interface{}{
+						"NewImage": map[string]interface{}{"status": map[string]interface{}{"S": "pending"}},
+					},
+				},
+			},
+		}
+
+		assert.True(t, route.Matches(event))
+
+		resp, err := route.Handle(context.TODO(), event)
+
+		assert.Nil(t, resp)
+		assert.Nil(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("IsDynamoDbTtlDeletion", func(t *testing.T) {
+		assert.True(t, routes.IsDynamoDbTtlDeletion(events.DynamoDBEventRecord{
+			EventName: routes.DynamoDbEventNameRemove,
+			UserIdentity: &events.DynamoDBUserIdentity{
+				Type:        "Service",
+				PrincipalID: "dynamodb.amazonaws.com",
+			},
+		}))
+		assert.False(t, routes.IsDynamoDbTtlDeletion(events.DynamoDBEventRecord{
+			EventName: routes.DynamoDbEventNameRemove,
+		}))
+		assert.False(t, routes.IsDynamoDbTtlDeletion(events.DynamoDBEventRecord{
+			EventName: routes.DynamoDbEventNameModify,
+			UserIdentity: &events.DynamoDBUserIdentity{
+				Type:        "Service",
+				PrincipalID: "dynamodb.amazonaws.com",
+			},
+		}))
 	})
 }
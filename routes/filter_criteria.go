@@ -0,0 +1,362 @@
+package routes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	filterOr                = "$or"
+	filterExists            = "exists"
+	filterPrefix            = "prefix"
+	filterSuffix            = "suffix"
+	filterEqualsIgnoreCase  = "equals-ignore-case"
+	filterAnythingBut       = "anything-but"
+	filterNumeric           = "numeric"
+	sqsFilterBodyField      = "body"
+	kinesisFilterField      = "kinesis"
+	kinesisFilterDataField  = "data"
+	filterNumericOperandLen = 2
+)
+
+var (
+	filterNumericOperators = map[string]func(value, operand float64) bool{
+		"=":  func(value, operand float64) bool { return value == operand },
+		">":  func(value, operand float64) bool { return value > operand },
+		">=": func(value, operand float64) bool { return value >= operand },
+		"<":  func(value, operand float64) bool { return value < operand },
+		"<=": func(value, operand float64) bool { return value <= operand },
+	}
+)
+
+// FilterCriteria filters batch records the same way as FilterCriteria of the Lambda event source mapping,
+// so records are filtered when events do not come from AWS, e.g. through bridges.NewSqsBridge.
+// Record passes if it matches any of the patterns.
+type FilterCriteria struct {
+	patterns []map[string]interface{}
+}
+
+// NewFilterCriteria compiles the filter patterns, e.g. `{"body": {"type": ["order"]}}`.
+// Supported are exact values, prefix, suffix, equals-ignore-case, anything-but, numeric, exists and $or.
+// JSON SQS message bodies and base64 encoded Kinesis data are decoded before matching,
+// DynamoDB records are matched as is, e.g. `{"dynamodb": {"NewImage": {"status": {"S": ["done"]}}}}`.
+func NewFilterCriteria(patterns ...string) (*FilterCriteria, error) {
+	criteria := &FilterCriteria{patterns: []map[string]interface{}{}}
+
+	for _, pattern := range patterns {
+		compiled := map[string]interface{}{}
+
+		if err := json.Unmarshal([]byte(pattern), &compiled); err != nil {
+			return nil, RouteCompileError.Wrap(err, "Invalid filter pattern given: %s", pattern)
+		}
+
+		if err := validateFilterPattern(compiled); err != nil {
+			return nil, RouteCompileError.Wrap(err, "Invalid filter pattern given: %s", pattern)
+		}
+
+		criteria.patterns = append(criteria.patterns, compiled)
+	}
+
+	return criteria, nil
+}
+
+// Matches tells if the record passes any of the patterns, records are matched in their JSON form.
+func (criteria *FilterCriteria) Matches(record interface{}) bool {
+	if len(criteria.patterns) == 0 {
+		return true
+	}
+
+	subject, ok := filterSubject(record)
+
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range criteria.patterns {
+		if matchFilterPattern(pattern, subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterSubject(record interface{}) (map[string]interface{}, bool) {
+	subject := map[string]interface{}{}
+	jsonRecord, err := json.Marshal(record)
+
+	if err != nil {
+		return nil, false
+	}
+
+	if err := json.Unmarshal(jsonRecord, &subject); err != nil {
+		return nil, false
+	}
+
+	if body, ok := subject[sqsFilterBodyField].(string); ok {
+		var decoded interface{}
+
+		if json.Unmarshal([]byte(body), &decoded) == nil {
+			subject[sqsFilterBodyField] = decoded
+		}
+	}
+
+	if kinesis, ok := subject[kinesisFilterField].(map[string]interface{}); ok {
+		subject = kinesis
+
+		if data, ok := subject[kinesisFilterDataField].(string); ok {
+			if decodedData, err := base64.StdEncoding.DecodeString(data); err == nil {
+				var decoded interface{}
+
+				if json.Unmarshal(decodedData, &decoded) == nil {
+					subject[kinesisFilterDataField] = decoded
+				} else {
+					subject[kinesisFilterDataField] = string(decodedData)
+				}
+			}
+		}
+	}
+
+	return subject, true
+}
+
+func validateFilterPattern(pattern map[string]interface{}) error {
+	for key, value := range pattern {
+		if key == filterOr {
+			alternatives, ok := value.([]interface{})
+
+			if !ok || len(alternatives) == 0 {
+				return RouteCompileError.New("%s expects a list of patterns", filterOr)
+			}
+
+			for _, alternative := range alternatives {
+				alternativePattern, ok := alternative.(map[string]interface{})
+
+				if !ok {
+					return RouteCompileError.New("%s expects a list of patterns", filterOr)
+				}
+
+				if err := validateFilterPattern(alternativePattern); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if err := validateFilterPattern(value); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, matcher := range value {
+				if err := validateFilterMatcher(matcher); err != nil {
+					return err
+				}
+			}
+		default:
+			return RouteCompileError.New("Expected an object or a list of values for %s", key)
+		}
+	}
+
+	return nil
+}
+
+func validateFilterMatcher(matcher interface{}) error {
+	if _, ok := matcher.([]interface{}); ok {
+		return RouteCompileError.New("Nested lists are not supported")
+	}
+
+	operation, ok := matcher.(map[string]interface{})
+
+	if !ok {
+		return nil
+	}
+
+	if len(operation) != 1 {
+		return RouteCompileError.New("Expected a single operator, got %v", operation)
+	}
+
+	for operator, operand := range operation {
+		switch operator {
+		case filterExists:
+			if _, ok := operand.(bool); !ok {
+				return RouteCompileError.New("%s expects a boolean", operator)
+			}
+		case filterPrefix, filterSuffix, filterEqualsIgnoreCase:
+			if _, ok := operand.(string); !ok {
+				return RouteCompileError.New("%s expects a string", operator)
+			}
+		case filterAnythingBut:
+			switch operand := operand.(type) {
+			case map[string]interface{}:
+				return validateFilterMatcher(operand)
+			case []interface{}:
+				for _, excluded := range operand {
+					if err := validateFilterMatcher(excluded); err != nil {
+						return err
+					}
+
+					if _, ok := excluded.(map[string]interface{}); ok {
+						return RouteCompileError.New("%s expects a list of values", operator)
+					}
+				}
+			}
+		case filterNumeric:
+			conditions, ok := operand.([]interface{})
+
+			if !ok || len(conditions) == 0 || len(conditions)%filterNumericOperandLen != 0 {
+				return RouteCompileError.New("%s expects operator and number pairs", operator)
+			}
+
+			for i := 0; i < len(conditions); i += filterNumericOperandLen {
+				numericOperator, _ := conditions[i].(string)
+
+				if _, ok := filterNumericOperators[numericOperator]; !ok {
+					return RouteCompileError.New("Unknown numeric operator %v", conditions[i])
+				}
+
+				if _, ok := conditions[i+1].(float64); !ok {
+					return RouteCompileError.New("%s expects a number after %s", operator, numericOperator)
+				}
+			}
+		default:
+			return RouteCompileError.New("Unknown filter operator %s", operator)
+		}
+	}
+
+	return nil
+}
+
+func matchFilterPattern(pattern map[string]interface{}, subject interface{}) bool {
+	fields, _ := subject.(map[string]interface{})
+
+	for key, value := range pattern {
+		if key == filterOr {
+			if !matchAnyFilterPattern(value.([]interface{}), subject) {
+				return false
+			}
+
+			continue
+		}
+
+		field, exists := fields[key]
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if !exists || !matchFilterPattern(value, field) {
+				return false
+			}
+		case []interface{}:
+			if !matchAnyFilterMatcher(value, field, exists) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchAnyFilterPattern(patterns []interface{}, subject interface{}) bool {
+	for _, pattern := range patterns {
+		if matchFilterPattern(pattern.(map[string]interface{}), subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAnyFilterMatcher(matchers []interface{}, field interface{}, exists bool) bool {
+	for _, matcher := range matchers {
+		if operation, ok := matcher.(map[string]interface{}); ok {
+			if _, ok := operation[filterExists]; ok {
+				if operation[filterExists].(bool) == exists {
+					return true
+				}
+
+				continue
+			}
+		}
+
+		if !exists {
+			continue
+		}
+
+		if values, ok := field.([]interface{}); ok {
+			for _, value := range values {
+				if matchFilterValue(matcher, value) {
+					return true
+				}
+			}
+
+			continue
+		}
+
+		if matchFilterValue(matcher, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchFilterValue(matcher interface{}, value interface{}) bool {
+	operation, ok := matcher.(map[string]interface{})
+
+	if !ok {
+		return matcher == value
+	}
+
+	for operator, operand := range operation {
+		switch operator {
+		case filterPrefix:
+			text, ok := value.(string)
+
+			return ok && strings.HasPrefix(text, operand.(string))
+		case filterSuffix:
+			text, ok := value.(string)
+
+			return ok && strings.HasSuffix(text, operand.(string))
+		case filterEqualsIgnoreCase:
+			text, ok := value.(string)
+
+			return ok && strings.EqualFold(text, operand.(string))
+		case filterAnythingBut:
+			switch operand := operand.(type) {
+			case []interface{}:
+				for _, excluded := range operand {
+					if excluded == value {
+						return false
+					}
+				}
+
+				return true
+			case map[string]interface{}:
+				return !matchFilterValue(operand, value)
+			default:
+				return operand != value
+			}
+		case filterNumeric:
+			number, ok := value.(float64)
+
+			if !ok {
+				return false
+			}
+
+			conditions := operand.([]interface{})
+
+			for i := 0; i < len(conditions); i += filterNumericOperandLen {
+				if !filterNumericOperators[conditions[i].(string)](number, conditions[i+1].(float64)) {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FilterCriteria(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewFilterCriteria returns an error on invalid pattern", func(t *testing.T) {
+		for _, pattern := range []string{
+			`not json`,
+			`{"body": "value"}`,
+			`{"body": [["value"]]}`,
+			`{"body": [{"unknown": "value"}]}`,
+			`{"body": [{"prefix": 1}]}`,
+			`{"body": [{"exists": "true"}]}`,
+			`{"body": [{"numeric": [">"]}]}`,
+			`{"body": [{"numeric": ["!", 1]}]}`,
+			`{"body": [{"anything-but": [{"prefix": "a"}]}]}`,
+			`{"$or": {"body": ["value"]}}`,
+		} {
+			_, err := routes.NewFilterCriteria(pattern)
+
+			require.Error(t, err, pattern)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError), pattern)
+		}
+	})
+
+	t.Run("Matches SQS messages", func(t *testing.T) {
+		message := events.SQSMessage{
+			MessageId: "1",
+			Body:      `{"type": "order", "amount": 10, "tags": ["new", "paid"], "customer": {"email": "John@Example.com"}}`,
+			MessageAttributes: map[string]events.SQSMessageAttribute{
+				"source": {DataType: "String", StringValue: stringPtr("shop")},
+			},
+		}
+
+		testCases := []struct {
+			pattern  string
+			expected bool
+		}{
+			{pattern: `{"body": {"type": ["order"]}}`, expected: true},
+			{pattern: `{"body": {"type": ["refund"]}}`, expected: false},
+			{pattern: `{"body": {"type": ["refund", "order"]}}`, expected: true},
+			{pattern: `{"body": {"type": ["order"], "amount": [5]}}`, expected: false},
+			{pattern: `{"body": {"type": [{"prefix": "ord"}]}}`, expected: true},
+			{pattern: `{"body": {"type": [{"suffix": "der"}]}}`, expected: true},
+			{pattern: `{"body": {"customer": {"email": [{"equals-ignore-case": "john@example.com"}]}}}`, expected: true},
+			{pattern: `{"body": {"type": [{"anything-but": ["order", "refund"]}]}}`, expected: false},
+			{pattern: `{"body": {"type": [{"anything-but": "refund"}]}}`, expected: true},
+			{pattern: `{"body": {"type": [{"anything-but": {"prefix": "ord"}}]}}`, expected: false},
+			{pattern: `{"body": {"amount": [{"numeric": [">", 5, "<=", 10]}]}}`, expected: true},
+			{pattern: `{"body": {"amount": [{"numeric": [">", 10]}]}}`, expected: false},
+			{pattern: `{"body": {"tags": ["paid"]}}`, expected: true},
+			{pattern: `{"body": {"missing": [{"exists": false}]}}`, expected: true},
+			{pattern: `{"body": {"type": [{"exists": false}]}}`, expected: false},
+			{pattern: `{"body": {"missing": ["value"]}}`, expected: false},
+			{pattern: `{"$or": [{"body": {"type": ["refund"]}}, {"body": {"amount": [10]}}]}`, expected: true},
+			{pattern: `{"messageAttributes": {"source": {"stringValue": ["shop"]}}}`, expected: true},
+		}
+
+		for _, testCase := range testCases {
+			criteria, err := routes.NewFilterCriteria(testCase.pattern)
+
+			require.Nil(t, err, testCase.pattern)
+			assert.Equal(t, testCase.expected, criteria.Matches(message), testCase.pattern)
+		}
+	})
+
+	t.Run("Matches plain text bodies", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(`{"body": [{"prefix": "hello"}]}`)
+
+		require.Nil(t, err)
+		assert.True(t, criteria.Matches(events.SQSMessage{Body: "hello world"}))
+		assert.False(t, criteria.Matches(events.SQSMessage{Body: "bye"}))
+	})
+
+	t.Run("Matches if any of the patterns match", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(`{"body": ["a"]}`, `{"body": ["b"]}`)
+
+		require.Nil(t, err)
+		assert.True(t, criteria.Matches(events.SQSMessage{Body: "b"}))
+		assert.False(t, criteria.Matches(events.SQSMessage{Body: "c"}))
+	})
+
+	t.Run("Matches DynamoDB records", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(
+			`{"eventName": ["MODIFY"], "dynamodb": {"NewImage": {"status": {"S": ["done"]}}}}`,
+		)
+
+		require.Nil(t, err)
+
+		record := func(eventName string, status string) events.DynamoDBEventRecord {
+			return events.DynamoDBEventRecord{
+				EventName: eventName,
+				Change: events.DynamoDBStreamRecord{
+					NewImage: map[string]events.DynamoDBAttributeValue{"status": events.NewStringAttribute(status)},
+				},
+			}
+		}
+
+		assert.True(t, criteria.Matches(record("MODIFY", "done")))
+		assert.False(t, criteria.Matches(record("MODIFY", "pending")))
+		assert.False(t, criteria.Matches(record("INSERT", "done")))
+	})
+
+	t.Run("Matches Kinesis records", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(`{"partitionKey": ["key"], "data": {"type": ["order"]}}`)
+
+		require.Nil(t, err)
+
+		record := func(data string) events.KinesisEventRecord {
+			return events.KinesisEventRecord{
+				Kinesis: events.KinesisRecord{PartitionKey: "key", Data: []byte(data)},
+			}
+		}
+
+		assert.True(t, criteria.Matches(record(`{"type": "order"}`)))
+		assert.False(t, criteria.Matches(record(`{"type": "refund"}`)))
+	})
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
@@ -3,14 +3,38 @@ package routes
 import (
 	"context"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
 )
 
 type GeneralHandlerFunc func(ctx context.Context, request interface{}) (interface{}, error)
 type ApiGatewayHandlerFunc func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+type ApiGatewayV2HandlerFunc func(ctx context.Context, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+type ApiGatewayMiddleware func(next ApiGatewayHandlerFunc) ApiGatewayHandlerFunc
+type JsonApiHandlerFunc[Req any, Resp any] func(ctx context.Context, request Req, event events.APIGatewayProxyRequest) (Resp, error)
+type ApiGatewayTokenAuthorizerHandlerFunc func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error)
+type ApiGatewayRequestAuthorizerHandlerFunc func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error)
 type DynamoDbHandlerFunc func(ctx context.Context, request events.DynamoDBEvent)
+type DynamoDbErrorHandlerFunc func(ctx context.Context, request events.DynamoDBEvent) error
+type DynamoDbBatchHandlerFunc func(ctx context.Context, request events.DynamoDBEvent) (events.DynamoDBEventResponse, error)
+type DynamoDbRecordHandlerFunc func(ctx context.Context, record events.DynamoDBEventRecord) error
 type SqsHandlerFunc func(ctx context.Context, request events.SQSEvent) error
+type SqsBatchHandlerFunc func(ctx context.Context, request events.SQSEvent) (events.SQSEventResponse, error)
+type SqsRecordHandlerFunc func(ctx context.Context, record events.SQSMessage) error
+type SqsMessageHandlerFunc func(ctx context.Context, message interface{}, record events.SQSMessage) error
+type TypedSqsHandlerFunc[Msg any] func(ctx context.Context, message Msg, record events.SQSMessage) error
+type SqsSinkFunc func(ctx context.Context, record events.SQSMessage, err error) error
+type SqsEnvelopeHandlerFunc func(ctx context.Context, record events.SQSMessage, envelope SqsEnvelope) error
 type CloudWatchScheduledEventHandlerFunc func(ctx context.Context, request events.CloudWatchEvent) error
+type CloudWatchLogsHandlerFunc func(ctx context.Context, request events.CloudwatchLogsData) error
+type CognitoPreSignupHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsPreSignup) (events.CognitoEventUserPoolsPreSignup, error)
+type CognitoPostConfirmationHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error)
+type CognitoPreTokenGenHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsPreTokenGen) (events.CognitoEventUserPoolsPreTokenGen, error)
+type CognitoCustomMessageHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsCustomMessage) (events.CognitoEventUserPoolsCustomMessage, error)
+type CognitoDefineAuthChallengeHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsDefineAuthChallenge) (events.CognitoEventUserPoolsDefineAuthChallenge, error)
+type CognitoCreateAuthChallengeHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsCreateAuthChallenge) (events.CognitoEventUserPoolsCreateAuthChallenge, error)
+type CognitoVerifyAuthChallengeHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsVerifyAuthChallenge) (events.CognitoEventUserPoolsVerifyAuthChallenge, error)
+type CognitoMigrateUserHandlerFunc func(ctx context.Context, request events.CognitoEventUserPoolsMigrateUser) (events.CognitoEventUserPoolsMigrateUser, error)
 
 type GeneralHandler interface {
 	Handle(ctx context.Context, request interface{}) (interface{}, error)
@@ -20,14 +44,94 @@ type ApiGatewayHandler interface {
 	Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
 }
 
+type ApiGatewayTokenAuthorizerHandler interface {
+	Handle(ctx context.Context, request events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error)
+}
+
+type ApiGatewayRequestAuthorizerHandler interface {
+	Handle(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error)
+}
+
 type DynamoDbHandler interface {
 	Handle(ctx context.Context, request events.DynamoDBEvent)
 }
 
+type DynamoDbErrorHandler interface {
+	Handle(ctx context.Context, request events.DynamoDBEvent) error
+}
+
+type DynamoDbBatchHandler interface {
+	Handle(ctx context.Context, request events.DynamoDBEvent) (events.DynamoDBEventResponse, error)
+}
+
+type DynamoDbRecordHandler interface {
+	Handle(ctx context.Context, record events.DynamoDBEventRecord) error
+}
+
 type SqsHandler interface {
 	Handle(ctx context.Context, request events.SQSEvent) error
 }
 
+type SqsBatchHandler interface {
+	Handle(ctx context.Context, request events.SQSEvent) (events.SQSEventResponse, error)
+}
+
+type SqsRecordHandler interface {
+	Handle(ctx context.Context, record events.SQSMessage) error
+}
+
+type SqsMessageHandler interface {
+	Handle(ctx context.Context, message interface{}, record events.SQSMessage) error
+}
+
+type SqsEnvelopeHandler interface {
+	Handle(ctx context.Context, record events.SQSMessage, envelope SqsEnvelope) error
+}
+
 type CloudWatchScheduledEventHandler interface {
 	Handle(ctx context.Context, request events.CloudWatchEvent) error
 }
+
+type CloudWatchLogsHandler interface {
+	Handle(ctx context.Context, request events.CloudwatchLogsData) error
+}
+
+type CognitoPreSignupHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsPreSignup) (events.CognitoEventUserPoolsPreSignup, error)
+}
+
+type CognitoPostConfirmationHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error)
+}
+
+type CognitoPreTokenGenHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsPreTokenGen) (events.CognitoEventUserPoolsPreTokenGen, error)
+}
+
+type CognitoCustomMessageHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsCustomMessage) (events.CognitoEventUserPoolsCustomMessage, error)
+}
+
+type CognitoDefineAuthChallengeHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsDefineAuthChallenge) (events.CognitoEventUserPoolsDefineAuthChallenge, error)
+}
+
+type CognitoCreateAuthChallengeHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsCreateAuthChallenge) (events.CognitoEventUserPoolsCreateAuthChallenge, error)
+}
+
+type CognitoVerifyAuthChallengeHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsVerifyAuthChallenge) (events.CognitoEventUserPoolsVerifyAuthChallenge, error)
+}
+
+type CognitoMigrateUserHandler interface {
+	Handle(ctx context.Context, request events.CognitoEventUserPoolsMigrateUser) (events.CognitoEventUserPoolsMigrateUser, error)
+}
+
+// CustomResourceHandler handles CloudFormation custom resource requests,
+// returned data is available through Fn::GetAtt in the template.
+type CustomResourceHandler interface {
+	Create(ctx context.Context, request cfn.Event) (physicalResourceId string, data map[string]interface{}, err error)
+	Update(ctx context.Context, request cfn.Event) (physicalResourceId string, data map[string]interface{}, err error)
+	Delete(ctx context.Context, request cfn.Event) (physicalResourceId string, data map[string]interface{}, err error)
+}
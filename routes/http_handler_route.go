@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// AnyHttpMethod matches requests with any HTTP method, like the ANY method of API Gateway.
+const AnyHttpMethod = "ANY"
+
+type apiGatewayRequestContextKey struct{}
+
+// NewHttpHandlerRoute creates an API Gateway route which serves the requests by the standard http.Handler,
+// e.g. http.ServeMux, chi or gorilla/mux routers. Use AnyHttpMethod to let the handler route the methods.
+// The handler sees the full request path including the prefixes of the route groups.
+func NewHttpHandlerRoute(path string, httpMethod string, handler http.Handler) (*ApiGatewayRoute, error) {
+	return NewApiGatewayRoute(path, httpMethod, HttpApiGatewayHandler(handler))
+}
+
+// HttpApiGatewayHandler converts the API Gateway request into *http.Request, serves it by the handler
+// and converts the recorded response back. Bodies which are not valid UTF-8 are responded base64 encoded.
+func HttpApiGatewayHandler(handler http.Handler) ApiGatewayHandlerFunc {
+	return func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request, err := NewHttpRequest(ctx, event)
+
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		recorder := newHttpResponseRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder.response(), nil
+	}
+}
+
+// NewHttpRequest converts the API Gateway request into *http.Request. Multi-value headers and query strings
+// are preferred over the single-value ones. The event can be read back by ApiGatewayRequestFromContext.
+func NewHttpRequest(ctx context.Context, event events.APIGatewayProxyRequest) (*http.Request, error) {
+	body := []byte(event.Body)
+
+	if event.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(event.Body)
+
+		if err != nil {
+			return nil, RouteUnmarshalError.Wrap(err, "Failed to decode base64 body")
+		}
+
+		body = decoded
+	}
+
+	requestUrl := &url.URL{Path: event.Path, RawQuery: httpQuery(event).Encode()}
+	ctx = context.WithValue(ctx, apiGatewayRequestContextKey{}, event)
+
+	request, err := http.NewRequestWithContext(ctx, event.HTTPMethod, requestUrl.String(), bytes.NewReader(body))
+
+	if err != nil {
+		return nil, RouteUnmarshalError.Wrap(err, "Failed to create HTTP request")
+	}
+
+	for name, values := range httpHeaders(event) {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+
+	request.Host = request.Header.Get("Host")
+	request.RequestURI = requestUrl.RequestURI()
+	request.RemoteAddr = event.RequestContext.Identity.SourceIP
+
+	return request, nil
+}
+
+// ApiGatewayRequestFromContext returns the API Gateway request of the *http.Request created by NewHttpRequest.
+func ApiGatewayRequestFromContext(ctx context.Context) (events.APIGatewayProxyRequest, bool) {
+	event, ok := ctx.Value(apiGatewayRequestContextKey{}).(events.APIGatewayProxyRequest)
+
+	return event, ok
+}
+
+func httpQuery(event events.APIGatewayProxyRequest) url.Values {
+	query := url.Values{}
+
+	for name, values := range event.MultiValueQueryStringParameters {
+		query[name] = append([]string{}, values...)
+	}
+
+	for name, value := range event.QueryStringParameters {
+		if _, ok := query[name]; !ok {
+			query.Set(name, value)
+		}
+	}
+
+	return query
+}
+
+func httpHeaders(event events.APIGatewayProxyRequest) http.Header {
+	headers := http.Header{}
+
+	for name, values := range event.MultiValueHeaders {
+		for _, value := range values {
+			headers.Add(name, value)
+		}
+	}
+
+	for name, value := range event.Headers {
+		if _, ok := headers[http.CanonicalHeaderKey(name)]; !ok {
+			headers.Set(name, value)
+		}
+	}
+
+	return headers
+}
+
+// httpResponseRecorder collects the response written by the http.Handler.
+type httpResponseRecorder struct {
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+}
+
+func newHttpResponseRecorder() *httpResponseRecorder {
+	return &httpResponseRecorder{header: http.Header{}}
+}
+
+func (recorder *httpResponseRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *httpResponseRecorder) Write(data []byte) (int, error) {
+	recorder.WriteHeader(http.StatusOK)
+
+	return recorder.body.Write(data)
+}
+
+func (recorder *httpResponseRecorder) WriteHeader(statusCode int) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = statusCode
+	}
+}
+
+// response puts headers with one value into Headers and the others into MultiValueHeaders,
+// API Gateway would duplicate values given in both.
+func (recorder *httpResponseRecorder) response() events.APIGatewayProxyResponse {
+	body := recorder.body.Bytes()
+
+	if recorder.header.Get(ContentTypeHeader) == "" && len(body) > 0 {
+		recorder.header.Set(ContentTypeHeader, http.DetectContentType(body))
+	}
+
+	response := events.APIGatewayProxyResponse{
+		StatusCode: recorder.statusCode,
+		Headers:    map[string]string{},
+		Body:       string(body),
+	}
+
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+
+	for name, values := range recorder.header {
+		if len(values) == 1 {
+			response.Headers[name] = values[0]
+
+			continue
+		}
+
+		if response.MultiValueHeaders == nil {
+			response.MultiValueHeaders = map[string][]string{}
+		}
+
+		response.MultiValueHeaders[name] = values
+	}
+
+	if !utf8.Valid(body) {
+		response.Body = base64.StdEncoding.EncodeToString(body)
+		response.IsBase64Encoded = true
+	}
+
+	return response
+}
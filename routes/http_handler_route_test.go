@@ -0,0 +1,149 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HttpHandlerRoute(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewHttpHandlerRoute", func(t *testing.T) {
+		t.Run("Returns an error if path does not compile to regexp", func(t *testing.T) {
+			_, err := routes.NewHttpHandlerRoute("[invalid regexp", routes.AnyHttpMethod, http.NotFoundHandler())
+
+			assert.Error(t, err)
+		})
+
+		t.Run("Matches any method with AnyHttpMethod", func(t *testing.T) {
+			route, err := routes.NewHttpHandlerRoute("^/users", routes.AnyHttpMethod, http.NotFoundHandler())
+
+			assert.NoError(t, err)
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/users"}))
+			assert.True(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodDelete, "path": "/users/1"}))
+			assert.False(t, route.Matches(map[string]interface{}{"httpMethod": http.MethodGet, "path": "/orders"}))
+		})
+
+		t.Run("Does not match events of other sources with AnyHttpMethod", func(t *testing.T) {
+			route, err := routes.NewHttpHandlerRoute("^/api", routes.AnyHttpMethod, http.NotFoundHandler())
+
+			assert.NoError(t, err)
+			assert.False(t, route.Matches(map[string]interface{}{"Records": []interface{}{}}))
+			assert.False(t, route.Matches(map[string]interface{}{"detail-type": "Scheduled Event"}))
+		})
+	})
+
+	t.Run("HttpApiGatewayHandler", func(t *testing.T) {
+		t.Run("Converts the request", func(t *testing.T) {
+			handler := routes.HttpApiGatewayHandler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				body, err := io.ReadAll(request.Body)
+
+				assert.NoError(t, err)
+				assert.Equal(t, http.MethodPost, request.Method)
+				assert.Equal(t, "/users", request.URL.Path)
+				assert.Equal(t, []string{"1", "2"}, request.URL.Query()["id"])
+				assert.Equal(t, "name", request.URL.Query().Get("sort"))
+				assert.Equal(t, []string{"a", "b"}, request.Header.Values("X-Tag"))
+				assert.Equal(t, "application/octet-stream", request.Header.Get("Content-Type"))
+				assert.Equal(t, "example.com", request.Host)
+				assert.Equal(t, "127.0.0.1", request.RemoteAddr)
+				assert.Equal(t, []byte{0xff, 0x00}, body)
+
+				event, ok := routes.ApiGatewayRequestFromContext(request.Context())
+
+				assert.True(t, ok)
+				assert.Equal(t, "/users", event.Path)
+
+				writer.WriteHeader(http.StatusNoContent)
+			}))
+
+			event := events.APIGatewayProxyRequest{
+				HTTPMethod:                      http.MethodPost,
+				Path:                            "/users",
+				Headers:                         map[string]string{"content-type": "application/octet-stream", "Host": "example.com"},
+				MultiValueHeaders:               map[string][]string{"x-tag": {"a", "b"}},
+				QueryStringParameters:           map[string]string{"id": "2", "sort": "name"},
+				MultiValueQueryStringParameters: map[string][]string{"id": {"1", "2"}},
+				Body:                            base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}),
+				IsBase64Encoded:                 true,
+				RequestContext: events.APIGatewayProxyRequestContext{
+					Identity: events.APIGatewayRequestIdentity{SourceIP: "127.0.0.1"},
+				},
+			}
+
+			response, err := handler(context.TODO(), event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		})
+
+		t.Run("Returns an error if base64 body is invalid", func(t *testing.T) {
+			handler := routes.HttpApiGatewayHandler(http.NotFoundHandler())
+
+			_, err := handler(context.TODO(), events.APIGatewayProxyRequest{
+				HTTPMethod:      http.MethodPost,
+				Path:            "/",
+				Body:            "not base64!",
+				IsBase64Encoded: true,
+			})
+
+			assert.Error(t, err)
+		})
+
+		t.Run("Converts the response", func(t *testing.T) {
+			handler := routes.HttpApiGatewayHandler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Add("Set-Cookie", "a=1")
+				writer.Header().Add("Set-Cookie", "b=2")
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusCreated)
+				_, _ = writer.Write([]byte(`{"id":1}`))
+			}))
+
+			response, err := handler(context.TODO(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Path: "/"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, events.APIGatewayProxyResponse{
+				StatusCode:        http.StatusCreated,
+				Headers:           map[string]string{"Content-Type": "application/json"},
+				MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+				Body:              `{"id":1}`,
+			}, response)
+		})
+
+		t.Run("Encodes binary response body with base64", func(t *testing.T) {
+			handler := routes.HttpApiGatewayHandler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				_, _ = writer.Write([]byte{0xff, 0xd8, 0xff})
+			}))
+
+			response, err := handler(context.TODO(), events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.True(t, response.IsBase64Encoded)
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0xd8, 0xff}), response.Body)
+		})
+
+		t.Run("Serves requests by http.ServeMux", func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users/", func(writer http.ResponseWriter, request *http.Request) {
+				_, _ = writer.Write([]byte("user " + request.URL.Path))
+			})
+
+			response, err := routes.HttpApiGatewayHandler(mux)(
+				context.TODO(),
+				events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/users/1"},
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "user /users/1", response.Body)
+			assert.Equal(t, "text/plain; charset=utf-8", response.Headers["Content-Type"])
+		})
+	})
+}
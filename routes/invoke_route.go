@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultInvokeDiscriminator = "action"
+	InvokePayloadField         = "payload"
+)
+
+// InvokeRoute dispatches direct lambda:Invoke and Step Functions task calls by the action name, e.g.
+// {"action": "resizeImage", "payload": {...}}
+type InvokeRoute struct {
+	discriminator string
+	actions       map[string]*invokeAction
+}
+
+type invokeAction struct {
+	requestType reflect.Type
+	handler     GeneralHandlerFunc
+}
+
+// NewInvokeRoute creates a route which reads the action name from the discriminator field,
+// DefaultInvokeDiscriminator is used if discriminator is empty.
+func NewInvokeRoute(discriminator string) *InvokeRoute {
+	if discriminator == "" {
+		discriminator = DefaultInvokeDiscriminator
+	}
+
+	return &InvokeRoute{
+		discriminator: discriminator,
+		actions:       map[string]*invokeAction{},
+	}
+}
+
+// AddAction registers a handler for the action.
+// Payload is decoded into a new value of the request type, e.g. passing ResizeImageRequest{} as a request
+// will call the handler with ResizeImageRequest. If request is nil, the raw payload is passed to the handler.
+func (route *InvokeRoute) AddAction(action string, request interface{}, handler GeneralHandlerFunc) *InvokeRoute {
+	route.actions[action] = &invokeAction{
+		requestType: reflect.TypeOf(request),
+		handler:     handler,
+	}
+
+	return route
+}
+
+func (route *InvokeRoute) AddActionHandler(action string, request interface{}, handler GeneralHandler) *InvokeRoute {
+	return route.AddAction(action, request, handler.Handle)
+}
+
+// Matches the events with the action name in the discriminator field, including the unknown actions.
+func (route *InvokeRoute) Matches(event map[string]interface{}) bool {
+	_, ok := event[route.discriminator].(string)
+
+	return ok
+}
+
+// Handle returns RouteUnknownActionError with the RouteActionProperty for the actions which are not added.
+func (route *InvokeRoute) Handle(ctx context.Context, event map[string]interface{}) (interface{}, error) {
+	actionName, ok := event[route.discriminator].(string)
+
+	if !ok {
+		return nil, RouteUnmarshalError.New("Field %s of the action name is not a string", route.discriminator)
+	}
+
+	action, ok := route.actions[actionName]
+
+	if !ok {
+		return nil, RouteUnknownActionError.
+			New("Unknown action %s", actionName).
+			WithProperty(RouteActionProperty, actionName)
+	}
+
+	request, err := action.decodePayload(event[InvokePayloadField])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return action.handler(ctx, request)
+}
+
+func (*InvokeRoute) HasResponse() bool {
+	return true
+}
+
+func (route *InvokeRoute) String() string {
+	actions := []string{}
+
+	for action := range route.actions {
+		actions = append(actions, action)
+	}
+
+	sort.Strings(actions)
+
+	return fmt.Sprintf("Invoke by %s: %s", route.discriminator, strings.Join(actions, ", "))
+}
+
+func (action *invokeAction) decodePayload(payload interface{}) (interface{}, error) {
+	if action.requestType == nil {
+		return payload, nil
+	}
+
+	request := reflect.New(action.requestType)
+
+	if payload == nil {
+		return request.Elem().Interface(), nil
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, RouteMarshalError.Wrap(err, "Failed to marshal payload to JSON")
+	}
+
+	err = json.Unmarshal(jsonPayload, request.Interface())
+
+	if err != nil {
+		return nil, RouteUnmarshalError.Wrap(err, "Failed to unmarshal payload from the JSON")
+	}
+
+	return request.Elem().Interface(), nil
+}
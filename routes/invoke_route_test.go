@@ -0,0 +1,214 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resizeImageRequest struct {
+	Key   string `json:"key"`
+	Width int    `json:"width"`
+}
+
+type resizeImageResponse struct {
+	Key string `json:"key"`
+}
+
+func Test_InvokeRoute(t *testing.T) {
+	t.Parallel()
+
+	nilHandler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	t.Run("HasResponse returns true", func(t *testing.T) {
+		assert.True(t, routes.NewInvokeRoute("").HasResponse())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		testCases := []struct {
+			description   string
+			discriminator string
+			event         map[string]interface{}
+			expected      bool
+		}{
+			{
+				description: "Empty event",
+				event:       map[string]interface{}{},
+				expected:    false,
+			},
+			{
+				description: "Discriminator is not a string",
+				event:       map[string]interface{}{"action": 1},
+				expected:    false,
+			},
+			{
+				description: "Default discriminator",
+				event:       map[string]interface{}{"action": "resizeImage"},
+				expected:    true,
+			},
+			{
+				description:   "Custom discriminator",
+				discriminator: "command",
+				event:         map[string]interface{}{"action": "resizeImage"},
+				expected:      false,
+			},
+			{
+				description:   "Custom discriminator",
+				discriminator: "command",
+				event:         map[string]interface{}{"command": "resizeImage"},
+				expected:      true,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				route := routes.NewInvokeRoute(testCase.discriminator).AddAction("resizeImage", nil, nilHandler)
+
+				assert.Equal(t, testCase.expected, route.Matches(testCase.event))
+			})
+		}
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		t.Run("Passes typed payload to the handler and returns its response", func(t *testing.T) {
+			requestCtx := context.TODO()
+
+			route := routes.NewInvokeRoute("").
+				AddAction("another", nil, nilHandler).
+				AddAction(
+					"resizeImage",
+					resizeImageRequest{},
+					func(ctx context.Context, request interface{}) (interface{}, error) {
+						assert.Equal(t, requestCtx, ctx)
+						assert.Equal(t, resizeImageRequest{Key: "image.png", Width: 100}, request)
+
+						return resizeImageResponse{Key: "image-100.png"}, nil
+					},
+				)
+
+			resp, err := route.Handle(requestCtx, map[string]interface{}{
+				"action": "resizeImage",
+				"payload": map[string]interface{}{
+					"key":   "image.png",
+					"width": 100,
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, resizeImageResponse{Key: "image-100.png"}, resp)
+		})
+
+		t.Run("Passes pointer payload to the handler", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction(
+				"resizeImage",
+				&resizeImageRequest{},
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					assert.Equal(t, &resizeImageRequest{Key: "image.png"}, request)
+
+					return nil, nil
+				},
+			)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{
+				"action":  "resizeImage",
+				"payload": map[string]interface{}{"key": "image.png"},
+			})
+
+			assert.Nil(t, err)
+		})
+
+		t.Run("Passes zero value if payload is not set", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction(
+				"resizeImage",
+				resizeImageRequest{},
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					assert.Equal(t, resizeImageRequest{}, request)
+
+					return nil, nil
+				},
+			)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{"action": "resizeImage"})
+
+			assert.Nil(t, err)
+		})
+
+		t.Run("Passes raw payload if request is nil", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction(
+				"resizeImage",
+				nil,
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					assert.Equal(t, map[string]interface{}{"key": "image.png"}, request)
+
+					return nil, nil
+				},
+			)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{
+				"action":  "resizeImage",
+				"payload": map[string]interface{}{"key": "image.png"},
+			})
+
+			assert.Nil(t, err)
+		})
+
+		t.Run("Returns an error from the handler", func(t *testing.T) {
+			handlerErr := errors.New("error")
+
+			route := routes.NewInvokeRoute("").AddAction(
+				"resizeImage",
+				nil,
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					return nil, handlerErr
+				},
+			)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{"action": "resizeImage"})
+
+			assert.Equal(t, handlerErr, err)
+		})
+
+		t.Run("Returns an error on unknown action", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction("resizeImage", nil, nilHandler)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{"action": "unknown"})
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnknownActionError))
+			assert.True(t, errorx.HasTrait(err, errorx.NotFound()))
+
+			action, ok := errorx.ExtractProperty(err, routes.RouteActionProperty)
+
+			assert.True(t, ok)
+			assert.Equal(t, "unknown", action)
+		})
+
+		t.Run("Returns an error if action name is not a string", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction("resizeImage", nil, nilHandler)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{"action": 1})
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnmarshalError))
+		})
+
+		t.Run("Returns an error if payload can not be decoded", func(t *testing.T) {
+			route := routes.NewInvokeRoute("").AddAction("resizeImage", resizeImageRequest{}, nilHandler)
+
+			_, err := route.Handle(context.TODO(), map[string]interface{}{
+				"action":  "resizeImage",
+				"payload": "invalid",
+			})
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteUnmarshalError))
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	ContentTypeHeader = "Content-Type"
+	JsonContentType   = "application/json"
+)
+
+type jsonApiErrorBody struct {
+	Message string           `json:"message"`
+	Errors  ValidationErrors `json:"errors,omitempty"`
+}
+
+// NewJsonApiRoute creates an API Gateway route which decodes the JSON body into Req and encodes Resp as JSON.
+// Invalid JSON and failed validation of the request, see Validator, are responded with 400 Bad Request
+// without calling the handler. Responses are 200 OK unless Resp implements HttpStatusCoder.
+func NewJsonApiRoute[Req any, Resp any](
+	path string,
+	httpMethod string,
+	handler JsonApiHandlerFunc[Req, Resp],
+) (*ApiGatewayRoute, error) {
+	route, err := NewApiGatewayRoute(path, httpMethod, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	route.handler = jsonApiHandler(handler)
+
+	return route, nil
+}
+
+// jsonApiHandler returns jsonApiBadRequest for the invalid requests, so the route handling the call,
+// e.g. a copy of the grouped route, responds with its own problem mapper.
+func jsonApiHandler[Req any, Resp any](handler JsonApiHandlerFunc[Req, Resp]) ApiGatewayHandlerFunc {
+	return func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request, err := decodeJsonBody[Req](event.Body, event.IsBase64Encoded)
+
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, jsonApiBadRequest{err: err}
+		}
+
+		response, err := handler(ctx, request, event)
+
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		statusCode := http.StatusOK
+
+		if statusCoder, ok := interface{}(response).(HttpStatusCoder); ok {
+			statusCode = statusCoder.HttpStatusCode()
+		}
+
+		return jsonApiResponse(statusCode, response)
+	}
+}
+
+// jsonApiBadRequest is responded with 400 Bad Request by the ApiGatewayRoute.
+type jsonApiBadRequest struct {
+	err error
+}
+
+func (badRequest jsonApiBadRequest) Error() string {
+	return badRequest.err.Error()
+}
+
+func jsonApiResponse(statusCode int, body interface{}) (events.APIGatewayProxyResponse, error) {
+	jsonBody, err := json.Marshal(body)
+
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, RouteMarshalError.Wrap(err, "Failed to marshal response to JSON")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    map[string]string{ContentTypeHeader: JsonContentType},
+		Body:       string(jsonBody),
+	}, nil
+}
+
+// jsonApiErrorResponse responds 400 Bad Request with field errors of the validation errors.
+func jsonApiErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
+	body := jsonApiErrorBody{Message: jsonApiErrorMessage(err)}
+
+	if errs, ok := AsValidationErrors(err); ok {
+		body.Errors = errs
+
+		if errorxErr, ok := err.(*errorx.Error); ok {
+			body.Message = errorxErr.Message()
+		}
+	}
+
+	return jsonApiResponse(http.StatusBadRequest, body)
+}
+
+func jsonApiErrorMessage(err error) string {
+	if errorxErr, ok := err.(*errorx.Error); ok {
+		if cause := errorxErr.Cause(); cause != nil {
+			return errorxErr.Message() + ": " + cause.Error()
+		}
+
+		return errorxErr.Message()
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,224 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createUserRequest struct {
+	Name string `json:"name"`
+}
+
+func (request createUserRequest) Validate() error {
+	if request.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
+
+type createUserResponse struct {
+	Id string `json:"id"`
+}
+
+func (createUserResponse) HttpStatusCode() int {
+	return http.StatusCreated
+}
+
+type getUserResponse struct {
+	Name string `json:"name"`
+}
+
+func Test_JsonApiRoute(t *testing.T) {
+	t.Parallel()
+
+	apiGatewayEvent := func(body string, isBase64Encoded bool) map[string]interface{} {
+		return map[string]interface{}{
+			"httpMethod":      http.MethodPost,
+			"path":            "/users",
+			"body":            body,
+			"isBase64Encoded": isBase64Encoded,
+		}
+	}
+
+	createUser := func(ctx context.Context, request createUserRequest, event events.APIGatewayProxyRequest) (createUserResponse, error) {
+		return createUserResponse{Id: request.Name + "Id"}, nil
+	}
+
+	t.Run("Matches by the path and the method", func(t *testing.T) {
+		route, err := routes.NewJsonApiRoute("^/users$", http.MethodPost, createUser)
+
+		require.Nil(t, err)
+		assert.True(t, route.Matches(apiGatewayEvent("", false)))
+		assert.True(t, route.HasResponse())
+	})
+
+	t.Run("Decodes the request and encodes the response", func(t *testing.T) {
+		testCases := []struct {
+			description     string
+			body            string
+			isBase64Encoded bool
+		}{
+			{description: "Plain body", body: `{"name": "john"}`},
+			{
+				description:     "Base64 encoded body",
+				body:            base64.StdEncoding.EncodeToString([]byte(`{"name": "john"}`)),
+				isBase64Encoded: true,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				route, err := routes.NewJsonApiRoute("^/users$", http.MethodPost, createUser)
+
+				require.Nil(t, err)
+
+				resp, err := route.Handle(context.TODO(), apiGatewayEvent(testCase.body, testCase.isBase64Encoded))
+
+				assert.Nil(t, err)
+				assert.Equal(t, events.APIGatewayProxyResponse{
+					StatusCode: http.StatusCreated,
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					Body:       `{"id":"johnId"}`,
+				}, resp)
+			})
+		}
+	})
+
+	t.Run("Responds 200 by default and decodes empty body into the zero value", func(t *testing.T) {
+		route, err := routes.NewJsonApiRoute(
+			"^/users$",
+			http.MethodPost,
+			func(ctx context.Context, request struct{}, event events.APIGatewayProxyRequest) (getUserResponse, error) {
+				return getUserResponse{Name: "john"}, nil
+			},
+		)
+
+		require.Nil(t, err)
+
+		resp, err := route.Handle(context.TODO(), apiGatewayEvent("", false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.(events.APIGatewayProxyResponse).StatusCode)
+		assert.Equal(t, `{"name":"john"}`, resp.(events.APIGatewayProxyResponse).Body)
+	})
+
+	t.Run("Responds 400 without calling the handler", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			body        string
+			message     string
+		}{
+			{
+				description: "If body is not a valid JSON",
+				body:        `not json`,
+				message:     "Failed to unmarshal body from the JSON: invalid character 'o' in literal null (expecting 'u')",
+			},
+			{
+				description: "If validation fails",
+				body:        `{"name": ""}`,
+				message:     "Validation failed: name is required",
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				route, err := routes.NewJsonApiRoute(
+					"^/users$",
+					http.MethodPost,
+					func(ctx context.Context, request createUserRequest, event events.APIGatewayProxyRequest) (createUserResponse, error) {
+						t.Fatal("Handler should not be called")
+
+						return createUserResponse{}, nil
+					},
+				)
+
+				require.Nil(t, err)
+
+				resp, err := route.Handle(context.TODO(), apiGatewayEvent(testCase.body, false))
+
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+				assert.Equal(t, "application/json", resp.(events.APIGatewayProxyResponse).Headers["Content-Type"])
+				assert.JSONEq(t, `{"message": "`+testCase.message+`"}`, resp.(events.APIGatewayProxyResponse).Body)
+			})
+		}
+	})
+
+	t.Run("Responds 400 with the problem mapper of the grouped route", func(t *testing.T) {
+		route, err := routes.NewJsonApiRoute("^/users$", http.MethodPost, createUser)
+
+		require.Nil(t, err)
+
+		grouped := route.Grouped("").WithProblemMapper(routes.NewProblemMapper())
+
+		resp, err := grouped.Handle(context.TODO(), apiGatewayEvent(`{"name": ""}`, false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+		assert.Equal(t, routes.ProblemContentType, resp.(events.APIGatewayProxyResponse).Headers["Content-Type"])
+
+		resp, err = route.Handle(context.TODO(), apiGatewayEvent(`{"name": ""}`, false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "application/json", resp.(events.APIGatewayProxyResponse).Headers["Content-Type"])
+	})
+
+	t.Run("Validates pointer requests", func(t *testing.T) {
+		route, err := routes.NewJsonApiRoute(
+			"^/users$",
+			http.MethodPost,
+			func(ctx context.Context, request *createUserRequest, event events.APIGatewayProxyRequest) (createUserResponse, error) {
+				if request == nil {
+					return createUserResponse{}, nil
+				}
+
+				return createUserResponse{Id: request.Name + "Id"}, nil
+			},
+		)
+
+		require.Nil(t, err)
+
+		resp, err := route.Handle(context.TODO(), apiGatewayEvent(`{"name": ""}`, false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.(events.APIGatewayProxyResponse).StatusCode)
+		assert.JSONEq(t, `{"message": "Validation failed: name is required"}`, resp.(events.APIGatewayProxyResponse).Body)
+
+		resp, err = route.Handle(context.TODO(), apiGatewayEvent(`{"name": "john"}`, false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"id":"johnId"}`, resp.(events.APIGatewayProxyResponse).Body)
+
+		resp, err = route.Handle(context.TODO(), apiGatewayEvent("", false))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.(events.APIGatewayProxyResponse).StatusCode)
+	})
+
+	t.Run("Returns an error from the handler", func(t *testing.T) {
+		handlerErr := errors.New("error")
+
+		route, err := routes.NewJsonApiRoute(
+			"^/users$",
+			http.MethodPost,
+			func(ctx context.Context, request createUserRequest, event events.APIGatewayProxyRequest) (createUserResponse, error) {
+				return createUserResponse{}, handlerErr
+			},
+		)
+
+		require.Nil(t, err)
+
+		_, err = route.Handle(context.TODO(), apiGatewayEvent(`{"name": "john"}`, false))
+
+		assert.Equal(t, handlerErr, err)
+	})
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+)
+
+// Validator is implemented by requests and messages which validate themselves after decoding.
+type Validator interface {
+	Validate() error
+}
+
+// HttpStatusCoder is implemented by responses which set the HTTP status code, e.g. http.StatusCreated.
+type HttpStatusCoder interface {
+	HttpStatusCode() int
+}
+
+// decodeJsonBody decodes the body into a new value of T and validates it if T or *T implements Validator.
+// Empty body decodes into the zero value, nil pointers are not validated.
+func decodeJsonBody[T any](body string, isBase64Encoded bool) (T, error) {
+	var value T
+
+	if isBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+
+		if err != nil {
+			return value, RouteUnmarshalError.Wrap(err, "Failed to decode base64 body")
+		}
+
+		body = string(decoded)
+	}
+
+	if body != "" {
+		if err := json.Unmarshal([]byte(body), &value); err != nil {
+			return value, RouteUnmarshalError.Wrap(err, "Failed to unmarshal body from the JSON")
+		}
+	}
+
+	// pointer types, e.g. *CreateUserRequest, implement Validator themselves
+	target := interface{}(&value)
+
+	if _, ok := target.(Validator); !ok {
+		target = value
+	}
+
+	if err := validate(target); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+func validate(value interface{}) error {
+	validator, ok := value.(Validator)
+
+	if !ok {
+		return nil
+	}
+
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Ptr && reflected.IsNil() {
+		return nil
+	}
+
+	if err := validator.Validate(); err != nil {
+		return RouteValidationError.Wrap(err, "Validation failed")
+	}
+
+	return nil
+}
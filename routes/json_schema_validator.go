@@ -0,0 +1,314 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the supported subset of the JSON Schema.
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+
+	types   []string
+	pattern *regexp.Regexp
+}
+
+// jsonSchemaKeywords are the keywords of the supported subset and the annotations which do not affect validation.
+var jsonSchemaKeywords = map[string]bool{
+	"type":                 true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+	"enum":                 true,
+	"minLength":            true,
+	"maxLength":            true,
+	"pattern":              true,
+	"minimum":              true,
+	"maximum":              true,
+	"exclusiveMinimum":     true,
+	"exclusiveMaximum":     true,
+	"minItems":             true,
+	"maxItems":             true,
+	"$schema":              true,
+	"$id":                  true,
+	"$comment":             true,
+	"title":                true,
+	"description":          true,
+	"default":              true,
+	"examples":             true,
+}
+
+// jsonSchemaTypes are the types of the JSON Schema.
+var jsonSchemaTypes = map[string]bool{
+	jsonTypeString:  true,
+	jsonTypeInteger: true,
+	jsonTypeNumber:  true,
+	jsonTypeBoolean: true,
+	jsonTypeObject:  true,
+	jsonTypeArray:   true,
+	jsonTypeNull:    true,
+}
+
+// NewJsonSchemaValidator creates a validator from the JSON Schema document. Supported keywords are
+// type, properties, required, additionalProperties (boolean), items, enum, minLength, maxLength, pattern,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum (numbers), minItems and maxItems.
+// Other keywords, e.g. $ref, oneOf or format, return an error instead of being ignored.
+func NewJsonSchemaValidator(schema string) (DocumentValidator, error) {
+	compiled := &jsonSchema{}
+
+	if err := json.Unmarshal([]byte(schema), compiled); err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid JSON Schema given")
+	}
+
+	if err := compiled.compile(); err != nil {
+		return nil, err
+	}
+
+	return compiled, nil
+}
+
+// UnmarshalJSON rejects the schemas which are not objects and the unsupported keywords.
+func (schema *jsonSchema) UnmarshalJSON(data []byte) error {
+	keywords := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &keywords); err != nil || keywords == nil {
+		return RouteCompileError.New("Schema must be an object, %s given", data)
+	}
+
+	for keyword := range keywords {
+		if !jsonSchemaKeywords[keyword] {
+			return RouteCompileError.New("Unsupported keyword %s given", keyword)
+		}
+	}
+
+	// the alias type has no UnmarshalJSON, so the fields are decoded as usual
+	type plainJsonSchema jsonSchema
+
+	return json.Unmarshal(data, (*plainJsonSchema)(schema))
+}
+
+func (schema *jsonSchema) ValidateDocument(document interface{}) ValidationErrors {
+	return schema.validate(document, "")
+}
+
+func (schema *jsonSchema) ValidateParameters(parameters map[string]string) ValidationErrors {
+	return schema.ValidateDocument(coerceParameters(parameters, func(name string) string {
+		if property, ok := schema.Properties[name]; ok && len(property.types) > 0 {
+			return property.types[0]
+		}
+
+		return jsonTypeString
+	}))
+}
+
+func (schema *jsonSchema) compile() error {
+	switch schemaType := schema.Type.(type) {
+	case nil:
+	case string:
+		schema.types = []string{schemaType}
+	case []interface{}:
+		for _, item := range schemaType {
+			name, ok := item.(string)
+
+			if !ok {
+				return RouteCompileError.New("Invalid type %v given", schemaType)
+			}
+
+			schema.types = append(schema.types, name)
+		}
+	default:
+		return RouteCompileError.New("Invalid type %v given", schemaType)
+	}
+
+	for _, name := range schema.types {
+		if !jsonSchemaTypes[name] {
+			return RouteCompileError.New("Unknown type %s given", name)
+		}
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+
+		if err != nil {
+			return RouteCompileError.Wrap(err, "Invalid pattern given")
+		}
+
+		schema.pattern = pattern
+	}
+
+	for name, property := range schema.Properties {
+		if property == nil {
+			return RouteCompileError.New("Schema of the property %s must be an object", name)
+		}
+
+		if err := property.compile(); err != nil {
+			return err
+		}
+	}
+
+	if schema.Items != nil {
+		return schema.Items.compile()
+	}
+
+	return nil
+}
+
+func (schema *jsonSchema) validate(value interface{}, path string) ValidationErrors {
+	if len(schema.types) > 0 && !schema.hasType(value) {
+		return ValidationErrors{{Field: path, Message: "must be " + strings.Join(schema.types, " or ")}}
+	}
+
+	errs := ValidationErrors{}
+
+	if len(schema.Enum) > 0 && !schema.inEnum(value) {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be one of %v", schema.Enum)})
+	}
+
+	switch value := value.(type) {
+	case string:
+		errs = append(errs, schema.validateString(value, path)...)
+	case float64:
+		errs = append(errs, schema.validateNumber(value, path)...)
+	case []interface{}:
+		errs = append(errs, schema.validateArray(value, path)...)
+	case map[string]interface{}:
+		errs = append(errs, schema.validateObject(value, path)...)
+	}
+
+	return errs
+}
+
+func (schema *jsonSchema) validateString(value string, path string) ValidationErrors {
+	errs := ValidationErrors{}
+	length := utf8.RuneCountInString(value)
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at least %d characters", *schema.MinLength)})
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at most %d characters", *schema.MaxLength)})
+	}
+
+	if schema.pattern != nil && !schema.pattern.MatchString(value) {
+		errs = append(errs, FieldError{Field: path, Message: "must match " + schema.Pattern})
+	}
+
+	return errs
+}
+
+func (schema *jsonSchema) validateNumber(value float64, path string) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if schema.Minimum != nil && value < *schema.Minimum {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at least %v", *schema.Minimum)})
+	}
+
+	if schema.Maximum != nil && value > *schema.Maximum {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at most %v", *schema.Maximum)})
+	}
+
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be greater than %v", *schema.ExclusiveMinimum)})
+	}
+
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be less than %v", *schema.ExclusiveMaximum)})
+	}
+
+	return errs
+}
+
+func (schema *jsonSchema) validateArray(value []interface{}, path string) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if schema.MinItems != nil && len(value) < *schema.MinItems {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must have at least %d items", *schema.MinItems)})
+	}
+
+	if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must have at most %d items", *schema.MaxItems)})
+	}
+
+	if schema.Items != nil {
+		for i, item := range value {
+			errs = append(errs, schema.Items.validate(item, validationIndexPath(path, i))...)
+		}
+	}
+
+	return errs
+}
+
+func (schema *jsonSchema) validateObject(value map[string]interface{}, path string) ValidationErrors {
+	errs := ValidationErrors{}
+
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			errs = append(errs, FieldError{Field: validationPath(path, name), Message: "is required"})
+		}
+	}
+
+	names := []string{}
+
+	for name := range value {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				errs = append(errs, FieldError{Field: validationPath(path, name), Message: "is not allowed"})
+			}
+
+			continue
+		}
+
+		errs = append(errs, property.validate(value[name], validationPath(path, name))...)
+	}
+
+	return errs
+}
+
+func (schema *jsonSchema) hasType(value interface{}) bool {
+	for _, expected := range schema.types {
+		if hasJsonType(value, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (schema *jsonSchema) inEnum(value interface{}) bool {
+	for _, expected := range schema.Enum {
+		if reflect.DeepEqual(expected, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+package routes_test
+
+import (
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userSchema = `{
+  "type": "object",
+  "required": ["name"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "minLength": 2, "maxLength": 5, "pattern": "^[a-z]+$"},
+    "age": {"type": "integer", "minimum": 18, "maximum": 100},
+    "score": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 10},
+    "role": {"enum": ["admin", "user"]},
+    "nickname": {"type": ["string", "null"]},
+    "tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+    "address": {
+      "type": "object",
+      "required": ["city"],
+      "properties": {"city": {"type": "string"}}
+    }
+  }
+}`
+
+func Test_JsonSchemaValidator(t *testing.T) {
+	t.Parallel()
+
+	validator, err := routes.NewJsonSchemaValidator(userSchema)
+	require.Nil(t, err)
+
+	t.Run("NewJsonSchemaValidator returns an error", func(t *testing.T) {
+		for _, schema := range []string{
+			`not json`,
+			`{"type": 1}`,
+			`{"type": [1]}`,
+			`{"type": "int"}`,
+			`{"properties": {"tags": {"type": ["array", "strnig"]}}}`,
+			`{"properties": {"name": {"pattern": "[["}}}`,
+			`{"items": {"type": {}}}`,
+			`null`,
+			`{"properties": {"name": null}}`,
+			`{"properties": {"name": true}}`,
+			`{"items": "string"}`,
+			`{"$ref": "#/definitions/user"}`,
+			`{"properties": {"name": {"type": "string", "format": "email"}}}`,
+			`{"items": {"oneOf": [{"type": "string"}, {"type": "number"}]}}`,
+		} {
+			_, err := routes.NewJsonSchemaValidator(schema)
+
+			require.Error(t, err, schema)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+		}
+	})
+
+	t.Run("ValidateDocument", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			document    interface{}
+			expected    routes.ValidationErrors
+		}{
+			{
+				description: "Valid document",
+				document: map[string]interface{}{
+					"name":     "john",
+					"age":      float64(20),
+					"score":    1.5,
+					"role":     "admin",
+					"nickname": nil,
+					"tags":     []interface{}{"a"},
+					"address":  map[string]interface{}{"city": "Vilnius"},
+				},
+				expected: routes.ValidationErrors{},
+			},
+			{
+				description: "Not an object",
+				document:    "john",
+				expected:    routes.ValidationErrors{{Field: "", Message: "must be object"}},
+			},
+			{
+				description: "Invalid values",
+				document: map[string]interface{}{
+					"age":      20.5,
+					"score":    float64(10),
+					"role":     "guest",
+					"nickname": float64(1),
+					"tags":     []interface{}{"a", "b", float64(1)},
+					"address":  map[string]interface{}{},
+					"unknown":  true,
+				},
+				expected: routes.ValidationErrors{
+					{Field: "name", Message: "is required"},
+					{Field: "address.city", Message: "is required"},
+					{Field: "age", Message: "must be integer"},
+					{Field: "nickname", Message: "must be string or null"},
+					{Field: "role", Message: "must be one of [admin user]"},
+					{Field: "score", Message: "must be less than 10"},
+					{Field: "tags", Message: "must have at most 2 items"},
+					{Field: "tags[2]", Message: "must be string"},
+					{Field: "unknown", Message: "is not allowed"},
+				},
+			},
+			{
+				description: "Invalid strings and numbers",
+				document: map[string]interface{}{
+					"name":  "JOHNNY",
+					"age":   float64(17),
+					"score": float64(0),
+					"tags":  []interface{}{},
+				},
+				expected: routes.ValidationErrors{
+					{Field: "age", Message: "must be at least 18"},
+					{Field: "name", Message: "must be at most 5 characters"},
+					{Field: "name", Message: "must match ^[a-z]+$"},
+					{Field: "score", Message: "must be greater than 0"},
+					{Field: "tags", Message: "must have at least 1 items"},
+				},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				assert.Equal(t, testCase.expected, validator.ValidateDocument(testCase.document))
+			})
+		}
+	})
+
+	t.Run("ValidateParameters converts parameters to the property types", func(t *testing.T) {
+		assert.Equal(t, routes.ValidationErrors{}, validator.ValidateParameters(map[string]string{
+			"name": "john",
+			"age":  "20",
+		}))
+		assert.Equal(t, routes.ValidationErrors{
+			{Field: "age", Message: "must be integer"},
+		}, validator.ValidateParameters(map[string]string{
+			"name": "john",
+			"age":  "twenty",
+		}))
+	})
+}
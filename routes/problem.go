@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	ProblemBlankType   = "about:blank"
+
+	problemInternalDetail = "An unexpected error occurred"
+	correlationIdBytes    = 16
+)
+
+var (
+	ProblemErrors = errorx.NewNamespace("problem")
+
+	ConflictTrait     = errorx.RegisterTrait("conflict")
+	UnauthorizedTrait = errorx.RegisterTrait("unauthorized")
+	ForbiddenTrait    = errorx.RegisterTrait("forbidden")
+	BadRequestTrait   = errorx.RegisterTrait("bad_request")
+
+	NotFoundError     = ProblemErrors.NewType("not_found", errorx.NotFound())
+	ConflictError     = ProblemErrors.NewType("conflict", ConflictTrait)
+	UnauthorizedError = ProblemErrors.NewType("unauthorized", UnauthorizedTrait)
+	ForbiddenError    = ProblemErrors.NewType("forbidden", ForbiddenTrait)
+	BadRequestError   = ProblemErrors.NewType("bad_request", BadRequestTrait)
+)
+
+// Problem is the RFC 7807 problem details object.
+type Problem struct {
+	Type          string           `json:"type"`
+	Title         string           `json:"title"`
+	Status        int              `json:"status"`
+	Detail        string           `json:"detail,omitempty"`
+	Instance      string           `json:"instance,omitempty"`
+	Code          string           `json:"code,omitempty"`
+	CorrelationId string           `json:"correlationId,omitempty"`
+	Errors        ValidationErrors `json:"errors,omitempty"`
+}
+
+// ProblemMapper converts errors returned by the handlers into application/problem+json responses.
+// Status code is taken from the first of: HttpStatusCoder implemented by the error or its causes,
+// registered errorx types, registered errorx traits. Other errors are responded with 500 without details,
+// the correlation ID in the response can be used to find the logged error.
+type ProblemMapper struct {
+	types  []problemTypeStatus
+	traits []problemTraitStatus
+	logf   func(format string, v ...interface{})
+}
+
+type problemTypeStatus struct {
+	errorType *errorx.Type
+	status    int
+}
+
+type problemTraitStatus struct {
+	trait  errorx.Trait
+	status int
+}
+
+// NewProblemMapper creates a mapper which knows the traits of this package, errorx.NotFound, errorx.Duplicate,
+// errorx.Timeout, errorx.Temporary and the validation, unmarshal and illegal argument errors.
+func NewProblemMapper() *ProblemMapper {
+	return &ProblemMapper{
+		types: []problemTypeStatus{
+			{errorType: RouteValidationError, status: http.StatusBadRequest},
+			{errorType: RouteUnmarshalError, status: http.StatusBadRequest},
+			{errorType: errorx.IllegalArgument, status: http.StatusBadRequest},
+			{errorType: errorx.IllegalFormat, status: http.StatusBadRequest},
+		},
+		traits: []problemTraitStatus{
+			{trait: BadRequestTrait, status: http.StatusBadRequest},
+			{trait: UnauthorizedTrait, status: http.StatusUnauthorized},
+			{trait: ForbiddenTrait, status: http.StatusForbidden},
+			{trait: errorx.NotFound(), status: http.StatusNotFound},
+			{trait: ConflictTrait, status: http.StatusConflict},
+			{trait: errorx.Duplicate(), status: http.StatusConflict},
+			{trait: errorx.Temporary(), status: http.StatusServiceUnavailable},
+			{trait: errorx.Timeout(), status: http.StatusGatewayTimeout},
+		},
+		logf: func(format string, v ...interface{}) {},
+	}
+}
+
+// WithType maps errors of the type and its subtypes to the status, takes precedence over previously added types.
+func (mapper *ProblemMapper) WithType(errorType *errorx.Type, status int) *ProblemMapper {
+	mapper.types = append([]problemTypeStatus{{errorType: errorType, status: status}}, mapper.types...)
+
+	return mapper
+}
+
+// WithTrait maps errors with the trait to the status, takes precedence over previously added traits.
+func (mapper *ProblemMapper) WithTrait(trait errorx.Trait, status int) *ProblemMapper {
+	mapper.traits = append([]problemTraitStatus{{trait: trait, status: status}}, mapper.traits...)
+
+	return mapper
+}
+
+// WithLogger logs errors responded with 500 together with the correlation ID, e.g. log.Printf.
+func (mapper *ProblemMapper) WithLogger(logf func(format string, v ...interface{})) *ProblemMapper {
+	mapper.logf = logf
+
+	return mapper
+}
+
+// Problem converts the error into the problem details.
+func (mapper *ProblemMapper) Problem(ctx context.Context, err error) Problem {
+	problem := Problem{
+		Type:          ProblemBlankType,
+		Status:        http.StatusInternalServerError,
+		CorrelationId: correlationId(ctx),
+	}
+
+	if errs, ok := AsValidationErrors(err); ok {
+		problem.Errors = errs
+	}
+
+	if status, ok := mapper.status(err); ok {
+		problem.Status = status
+		problem.Title = http.StatusText(status)
+		problem.Code = errorx.GetTypeName(err)
+		problem.Detail = err.Error()
+
+		if errorxErr := errorx.Cast(err); errorxErr != nil {
+			problem.Detail = errorxErr.Message()
+		}
+
+		return problem
+	}
+
+	mapper.logf("Unexpected error, correlation ID %s: %+v", problem.CorrelationId, err)
+
+	problem.Title = http.StatusText(http.StatusInternalServerError)
+	problem.Detail = problemInternalDetail
+
+	return problem
+}
+
+// Response converts the error into the API Gateway response with the problem details.
+func (mapper *ProblemMapper) Response(ctx context.Context, err error) events.APIGatewayProxyResponse {
+	problem := mapper.Problem(ctx, err)
+	body, _ := json.Marshal(problem)
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: problem.Status,
+		Headers:    map[string]string{ContentTypeHeader: ProblemContentType},
+		Body:       string(body),
+	}
+}
+
+func (mapper *ProblemMapper) status(err error) (int, bool) {
+	for cause := err; cause != nil; {
+		if statusCoder, ok := cause.(HttpStatusCoder); ok {
+			return statusCoder.HttpStatusCode(), true
+		}
+
+		errorxErr, ok := cause.(*errorx.Error)
+
+		if !ok {
+			break
+		}
+
+		cause = errorxErr.Cause()
+	}
+
+	for _, typeStatus := range mapper.types {
+		if errorx.IsOfType(err, typeStatus.errorType) {
+			return typeStatus.status, true
+		}
+	}
+
+	for _, traitStatus := range mapper.traits {
+		if errorx.HasTrait(err, traitStatus.trait) {
+			return traitStatus.status, true
+		}
+	}
+
+	return 0, false
+}
+
+// correlationId returns the Lambda request ID or a random ID outside of Lambda.
+func correlationId(ctx context.Context) string {
+	if lambdaContext, ok := lambdacontext.FromContext(ctx); ok && lambdaContext.AwsRequestID != "" {
+		return lambdaContext.AwsRequestID
+	}
+
+	id := make([]byte, correlationIdBytes)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
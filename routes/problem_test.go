@@ -0,0 +1,219 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type paymentRequiredError struct{}
+
+func (paymentRequiredError) Error() string {
+	return "payment required"
+}
+
+func (paymentRequiredError) HttpStatusCode() int {
+	return http.StatusPaymentRequired
+}
+
+func Test_ProblemMapper(t *testing.T) {
+	t.Parallel()
+
+	lambdaCtx := lambdacontext.NewContext(context.TODO(), &lambdacontext.LambdaContext{AwsRequestID: "requestId"})
+	teapotTrait := errorx.RegisterTrait("teapot")
+	teapotError := errorx.NewNamespace("test").NewType("teapot", teapotTrait)
+
+	t.Run("Maps errors to the problems", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			err         error
+			expected    routes.Problem
+		}{
+			{
+				description: "Not found trait",
+				err:         routes.NotFoundError.New("User %s not found", "1"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Not Found",
+					Status: http.StatusNotFound,
+					Detail: "User 1 not found",
+					Code:   "problem.not_found",
+				},
+			},
+			{
+				description: "Conflict trait",
+				err:         routes.ConflictError.New("Already exists"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Conflict",
+					Status: http.StatusConflict,
+					Detail: "Already exists",
+					Code:   "problem.conflict",
+				},
+			},
+			{
+				description: "Unauthorized trait",
+				err:         routes.UnauthorizedError.New("Token expired"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Unauthorized",
+					Status: http.StatusUnauthorized,
+					Detail: "Token expired",
+					Code:   "problem.unauthorized",
+				},
+			},
+			{
+				description: "Trait of another namespace",
+				err:         routes.RouteUnknownActionError.New("Unknown action"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Not Found",
+					Status: http.StatusNotFound,
+					Detail: "Unknown action",
+					Code:   "route.unknown_action",
+				},
+			},
+			{
+				description: "Validation errors",
+				err:         routes.RouteValidationError.Wrap(routes.ValidationErrors{{Field: "name", Message: "is required"}}, "Validation failed"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Bad Request",
+					Status: http.StatusBadRequest,
+					Detail: "Validation failed",
+					Code:   "route.validation",
+					Errors: routes.ValidationErrors{{Field: "name", Message: "is required"}},
+				},
+			},
+			{
+				description: "Status interface",
+				err:         paymentRequiredError{},
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Payment Required",
+					Status: http.StatusPaymentRequired,
+					Detail: "payment required",
+				},
+			},
+			{
+				description: "Status interface of the cause",
+				err:         errorx.Decorate(paymentRequiredError{}, "decorated"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Payment Required",
+					Status: http.StatusPaymentRequired,
+					Detail: "decorated",
+				},
+			},
+			{
+				description: "Registered trait",
+				err:         teapotError.New("Teapot"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "I'm a teapot",
+					Status: http.StatusTeapot,
+					Detail: "Teapot",
+					Code:   "test.teapot",
+				},
+			},
+			{
+				description: "Unknown error",
+				err:         errors.New("secret"),
+				expected: routes.Problem{
+					Type:   "about:blank",
+					Title:  "Internal Server Error",
+					Status: http.StatusInternalServerError,
+					Detail: "An unexpected error occurred",
+				},
+			},
+		}
+
+		mapper := routes.NewProblemMapper().WithTrait(teapotTrait, http.StatusTeapot)
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				problem := mapper.Problem(lambdaCtx, testCase.err)
+
+				testCase.expected.CorrelationId = "requestId"
+				assert.Equal(t, testCase.expected, problem)
+			})
+		}
+	})
+
+	t.Run("Registered types take precedence", func(t *testing.T) {
+		mapper := routes.NewProblemMapper().WithType(routes.NotFoundError, http.StatusGone)
+
+		assert.Equal(t, http.StatusGone, mapper.Problem(lambdaCtx, routes.NotFoundError.New("Gone")).Status)
+	})
+
+	t.Run("Logs unknown errors with the correlation ID", func(t *testing.T) {
+		logged := ""
+		mapper := routes.NewProblemMapper().WithLogger(func(format string, v ...interface{}) {
+			logged = fmt.Sprintf(format, v...)
+		})
+
+		problem := mapper.Problem(context.TODO(), errors.New("secret"))
+
+		assert.NotEmpty(t, problem.CorrelationId)
+		assert.Contains(t, logged, problem.CorrelationId)
+		assert.Contains(t, logged, "secret")
+	})
+
+	t.Run("Response", func(t *testing.T) {
+		response := routes.NewProblemMapper().Response(lambdaCtx, routes.ConflictError.New("Already exists"))
+
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+		assert.Equal(t, "application/problem+json", response.Headers["Content-Type"])
+		assert.JSONEq(t, `{
+  "type": "about:blank",
+  "title": "Conflict",
+  "status": 409,
+  "detail": "Already exists",
+  "code": "problem.conflict",
+  "correlationId": "requestId"
+}`, response.Body)
+	})
+
+	t.Run("API routes respond with problems", func(t *testing.T) {
+		route, err := routes.NewJsonApiRoute(
+			"^/users$",
+			http.MethodPost,
+			func(ctx context.Context, request createUserRequest, event events.APIGatewayProxyRequest) (createUserResponse, error) {
+				return createUserResponse{}, routes.ConflictError.New("User %s exists", request.Name)
+			},
+		)
+
+		require.Nil(t, err)
+
+		route.WithProblemMapper(routes.NewProblemMapper())
+
+		event := map[string]interface{}{"httpMethod": http.MethodPost, "path": "/users", "body": `{"name": "john"}`}
+		resp, err := route.Handle(lambdaCtx, event)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusConflict, resp.(events.APIGatewayProxyResponse).StatusCode)
+		assert.Equal(t, "application/problem+json", resp.(events.APIGatewayProxyResponse).Headers["Content-Type"])
+
+		event["body"] = `{"name": ""}`
+		resp, err = route.Handle(lambdaCtx, event)
+
+		assert.Nil(t, err)
+
+		problem := routes.Problem{}
+		require.Nil(t, json.Unmarshal([]byte(resp.(events.APIGatewayProxyResponse).Body), &problem))
+
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Equal(t, "problem.bad_request", problem.Code)
+		assert.Equal(t, "Validation failed: name is required", problem.Detail)
+	})
+}
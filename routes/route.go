@@ -2,6 +2,7 @@ package routes
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/joomcode/errorx"
 )
@@ -9,9 +10,15 @@ import (
 var (
 	RouteErrors = errorx.NewNamespace("route")
 
-	RouteCompileError   = RouteErrors.NewType("route_compile")
-	RouteMarshalError   = RouteErrors.NewType("marshal")
-	RouteUnmarshalError = RouteErrors.NewType("unmarshal")
+	RouteCompileError       = RouteErrors.NewType("route_compile")
+	RouteMarshalError       = RouteErrors.NewType("marshal")
+	RouteUnmarshalError     = RouteErrors.NewType("unmarshal")
+	RouteUnknownActionError = RouteErrors.NewType("unknown_action", errorx.NotFound())
+	RouteResponseError      = RouteErrors.NewType("response")
+	RouteHandlerPanicError  = RouteErrors.NewType("handler_panic")
+	RouteValidationError    = RouteErrors.NewType("validation")
+
+	RouteActionProperty = errorx.RegisterProperty("action")
 )
 
 type Route interface {
@@ -19,3 +26,29 @@ type Route interface {
 	Handle(ctx context.Context, event map[string]interface{}) (interface{}, error)
 	HasResponse() bool
 }
+
+func unmarshalEvent(event map[string]interface{}, request interface{}) error {
+	jsonEvent, err := json.Marshal(event)
+
+	if err != nil {
+		return RouteMarshalError.Wrap(err, "Failed to marshal event to JSON")
+	}
+
+	err = json.Unmarshal(jsonEvent, request)
+
+	if err != nil {
+		return RouteUnmarshalError.Wrap(err, "Failed to unmarshal request from the JSON")
+	}
+
+	return nil
+}
+
+func hasString(values []string, value string) bool {
+	for _, expected := range values {
+		if expected == value {
+			return true
+		}
+	}
+
+	return false
+}
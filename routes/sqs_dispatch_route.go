@@ -0,0 +1,148 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// SqsTypeResolver reads the message type used to pick the handler of the message.
+type SqsTypeResolver func(record events.SQSMessage) (string, bool)
+
+// SqsDispatchRoute dispatches messages of the same queue to the handlers registered for their types.
+// Messages are handled one by one as in NewSqsRecordRoute and failures of all handlers are reported
+// as batch item failures of the single response.
+type SqsDispatchRoute struct {
+	*SqsRoute
+	resolver       SqsTypeResolver
+	messageTypes   map[string]*sqsMessageType
+	defaultHandler SqsRecordHandlerFunc
+}
+
+type sqsMessageType struct {
+	messageType reflect.Type
+	handler     SqsMessageHandlerFunc
+}
+
+// SqsMessageAttributeType resolves the message type from the string message attribute, e.g. "type".
+func SqsMessageAttributeType(attribute string) SqsTypeResolver {
+	return func(record events.SQSMessage) (string, bool) {
+		messageAttribute, ok := record.MessageAttributes[attribute]
+
+		if !ok || messageAttribute.StringValue == nil {
+			return "", false
+		}
+
+		return *messageAttribute.StringValue, true
+	}
+}
+
+// SqsBodyFieldType resolves the message type from the string field of the JSON message body, e.g. "type".
+func SqsBodyFieldType(field string) SqsTypeResolver {
+	return func(record events.SQSMessage) (string, bool) {
+		body := map[string]interface{}{}
+
+		if err := json.Unmarshal([]byte(record.Body), &body); err != nil {
+			return "", false
+		}
+
+		messageType, ok := body[field].(string)
+
+		return messageType, ok
+	}
+}
+
+func NewSqsDispatchRoute(eventSourceArn string, resolver SqsTypeResolver) (*SqsDispatchRoute, error) {
+	route := &SqsDispatchRoute{
+		resolver:     resolver,
+		messageTypes: map[string]*sqsMessageType{},
+	}
+
+	sqsRoute, err := NewSqsRecordRoute(eventSourceArn, route.handleRecord)
+
+	if err != nil {
+		return nil, err
+	}
+
+	route.SqsRoute = sqsRoute
+
+	return route, nil
+}
+
+// AddType registers a handler for the message type.
+// Body is decoded into a new value of the message type, e.g. passing OrderCreated{} as a message
+// will call the handler with OrderCreated. If message is nil, the raw body is passed to the handler.
+func (route *SqsDispatchRoute) AddType(messageType string, message interface{}, handler SqsMessageHandlerFunc) *SqsDispatchRoute {
+	route.messageTypes[messageType] = &sqsMessageType{
+		messageType: reflect.TypeOf(message),
+		handler:     handler,
+	}
+
+	return route
+}
+
+func (route *SqsDispatchRoute) AddTypeHandler(messageType string, message interface{}, handler SqsMessageHandler) *SqsDispatchRoute {
+	return route.AddType(messageType, message, handler.Handle)
+}
+
+// SetDefaultHandler sets a handler for messages of unknown types,
+// without it such messages are reported as failed.
+func (route *SqsDispatchRoute) SetDefaultHandler(handler SqsRecordHandlerFunc) *SqsDispatchRoute {
+	route.defaultHandler = handler
+
+	return route
+}
+
+func (route *SqsDispatchRoute) String() string {
+	messageTypes := []string{}
+
+	for messageType := range route.messageTypes {
+		messageTypes = append(messageTypes, messageType)
+	}
+
+	sort.Strings(messageTypes)
+
+	return fmt.Sprintf("%s dispatched to: %s", route.SqsRoute.String(), strings.Join(messageTypes, ", "))
+}
+
+func (route *SqsDispatchRoute) handleRecord(ctx context.Context, record events.SQSMessage) error {
+	typeName, _ := route.resolver(record)
+	messageType, ok := route.messageTypes[typeName]
+
+	if !ok {
+		if route.defaultHandler != nil {
+			return route.defaultHandler(ctx, record)
+		}
+
+		return RouteUnknownActionError.
+			New("Unknown message type %s", typeName).
+			WithProperty(RouteActionProperty, typeName)
+	}
+
+	message, err := messageType.decodeBody(record.Body)
+
+	if err != nil {
+		return err
+	}
+
+	return messageType.handler(ctx, message, record)
+}
+
+func (messageType *sqsMessageType) decodeBody(body string) (interface{}, error) {
+	if messageType.messageType == nil {
+		return body, nil
+	}
+
+	message := reflect.New(messageType.messageType)
+
+	if err := json.Unmarshal([]byte(body), message.Interface()); err != nil {
+		return nil, RouteUnmarshalError.Wrap(err, "Failed to unmarshal message from the JSON")
+	}
+
+	return message.Elem().Interface(), nil
+}
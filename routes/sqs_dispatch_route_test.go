@@ -0,0 +1,159 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	Type    string `json:"type"`
+	OrderId string `json:"orderId"`
+}
+
+type orderCancelled struct {
+	OrderId string `json:"orderId"`
+	Reason  string `json:"reason"`
+}
+
+func Test_SqsDispatchRoute(t *testing.T) {
+	t.Parallel()
+
+	sqsRecord := func(messageId string, body string, messageType string) map[string]interface{} {
+		record := map[string]interface{}{
+			"messageId":      messageId,
+			"eventSourceARN": "arn:aws:sqs:us-east-2:123456789012:orders",
+			"body":           body,
+		}
+
+		if messageType != "" {
+			record["messageAttributes"] = map[string]interface{}{
+				"type": map[string]interface{}{"dataType": "String", "stringValue": messageType},
+			}
+		}
+
+		return record
+	}
+
+	t.Run("NewSqsDispatchRoute returns an error if invalid regexp is passed", func(t *testing.T) {
+		_, err := routes.NewSqsDispatchRoute("[[", routes.SqsBodyFieldType("type"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("HasResponse returns true", func(t *testing.T) {
+		route, err := routes.NewSqsDispatchRoute(".*", routes.SqsBodyFieldType("type"))
+
+		require.Nil(t, err)
+		assert.True(t, route.HasResponse())
+	})
+
+	t.Run("Matches by the event source ARN", func(t *testing.T) {
+		route, err := routes.NewSqsDispatchRoute(":orders$", routes.SqsBodyFieldType("type"))
+
+		require.Nil(t, err)
+		assert.True(t, route.Matches(map[string]interface{}{
+			"Records": []interface{}{sqsRecord("1", "{}", "")},
+		}))
+		assert.False(t, route.Matches(map[string]interface{}{
+			"Records": []interface{}{map[string]interface{}{"eventSourceARN": "arn:aws:sqs:us-east-2:123456789012:another"}},
+		}))
+	})
+
+	t.Run("Dispatches messages by the body field", func(t *testing.T) {
+		created := []orderCreated{}
+		cancelled := []orderCancelled{}
+
+		route, err := routes.NewSqsDispatchRoute(".*", routes.SqsBodyFieldType("type"))
+
+		require.Nil(t, err)
+
+		route.
+			AddType("created", orderCreated{}, func(ctx context.Context, message interface{}, record events.SQSMessage) error {
+				created = append(created, message.(orderCreated))
+
+				return nil
+			}).
+			AddType("cancelled", orderCancelled{}, func(ctx context.Context, message interface{}, record events.SQSMessage) error {
+				cancelled = append(cancelled, message.(orderCancelled))
+
+				return errors.New("error")
+			})
+
+		resp, err := route.Handle(context.TODO(), map[string]interface{}{
+			"Records": []interface{}{
+				sqsRecord("1", `{"type": "created", "orderId": "1"}`, ""),
+				sqsRecord("2", `{"type": "cancelled", "orderId": "2", "reason": "reason"}`, ""),
+				sqsRecord("3", `{"type": "unknown"}`, ""),
+				sqsRecord("4", `not json`, ""),
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []orderCreated{{Type: "created", OrderId: "1"}}, created)
+		assert.Equal(t, []orderCancelled{{OrderId: "2", Reason: "reason"}}, cancelled)
+		assert.Equal(t, events.SQSEventResponse{
+			BatchItemFailures: []events.SQSBatchItemFailure{
+				{ItemIdentifier: "2"},
+				{ItemIdentifier: "3"},
+				{ItemIdentifier: "4"},
+			},
+		}, resp)
+	})
+
+	t.Run("Dispatches messages by the message attribute", func(t *testing.T) {
+		handled := map[string]interface{}{}
+
+		route, err := routes.NewSqsDispatchRoute(".*", routes.SqsMessageAttributeType("type"))
+
+		require.Nil(t, err)
+
+		route.
+			AddType("created", orderCreated{}, func(ctx context.Context, message interface{}, record events.SQSMessage) error {
+				handled[record.MessageId] = message
+
+				return nil
+			}).
+			AddType("raw", nil, func(ctx context.Context, message interface{}, record events.SQSMessage) error {
+				handled[record.MessageId] = message
+
+				return nil
+			}).
+			SetDefaultHandler(func(ctx context.Context, record events.SQSMessage) error {
+				handled[record.MessageId] = "default"
+
+				return nil
+			})
+
+		resp, err := route.Handle(context.TODO(), map[string]interface{}{
+			"Records": []interface{}{
+				sqsRecord("1", `{"orderId": "1"}`, "created"),
+				sqsRecord("2", `raw body`, "raw"),
+				sqsRecord("3", `{}`, ""),
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"1": orderCreated{OrderId: "1"},
+			"2": "raw body",
+			"3": "default",
+		}, handled)
+		assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}, resp)
+	})
+
+	t.Run("String lists message types", func(t *testing.T) {
+		route, err := routes.NewSqsDispatchRoute("orders", routes.SqsBodyFieldType("type"))
+
+		require.Nil(t, err)
+
+		route.AddType("b", nil, nil).AddType("a", nil, nil)
+
+		assert.Equal(t, "SQS event orders dispatched to: a, b", route.String())
+	})
+}
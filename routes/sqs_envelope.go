@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	SqsEnvelopeNone        = ""
+	SqsEnvelopeSns         = "sns"
+	SqsEnvelopeEventBridge = "eventbridge"
+
+	snsNotificationType        = "Notification"
+	snsBinaryAttributeType     = "Binary"
+	snsTypeField               = "Type"
+	snsValueField              = "Value"
+	snsTopicArnField           = "TopicArn"
+	snsMessageField            = "Message"
+	eventBridgeDetailField     = "detail"
+	eventBridgeDetailTypeField = "detail-type"
+	eventBridgeSourceField     = "source"
+)
+
+// SqsEnvelope describes the envelope in which SNS or EventBridge delivered the message to the queue.
+// Messages delivered by SNS with the raw message delivery enabled have no envelope.
+type SqsEnvelope struct {
+	Type        string
+	Sns         *events.SNSEntity
+	EventBridge *events.CloudWatchEvent
+}
+
+// UnwrapSqsMessage replaces the body of the record with the message from the SNS notification
+// or the detail of the EventBridge event. SNS message attributes are added to the message attributes
+// of the record, so they can be read the same way as with the raw message delivery.
+// Records without an envelope are returned as is.
+func UnwrapSqsMessage(record events.SQSMessage) (events.SQSMessage, SqsEnvelope) {
+	body := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal([]byte(record.Body), &body); err != nil {
+		return record, SqsEnvelope{Type: SqsEnvelopeNone}
+	}
+
+	switch {
+	case isSnsEnvelope(body):
+		notification := events.SNSEntity{}
+
+		if err := json.Unmarshal([]byte(record.Body), &notification); err != nil {
+			return record, SqsEnvelope{Type: SqsEnvelopeNone}
+		}
+
+		record.Body = notification.Message
+		record.MessageAttributes = mergeSnsMessageAttributes(record.MessageAttributes, notification.MessageAttributes)
+
+		return record, SqsEnvelope{Type: SqsEnvelopeSns, Sns: &notification}
+	case isEventBridgeEnvelope(body):
+		event := events.CloudWatchEvent{}
+
+		if err := json.Unmarshal([]byte(record.Body), &event); err != nil {
+			return record, SqsEnvelope{Type: SqsEnvelopeNone}
+		}
+
+		record.Body = string(event.Detail)
+
+		return record, SqsEnvelope{Type: SqsEnvelopeEventBridge, EventBridge: &event}
+	}
+
+	return record, SqsEnvelope{Type: SqsEnvelopeNone}
+}
+
+// NewSqsEnvelopeRoute creates a route which calls the handler for every unwrapped message
+// together with its envelope, see UnwrapSqsMessage and NewSqsRecordRoute.
+func NewSqsEnvelopeRoute(eventSourceArn string, handler SqsEnvelopeHandlerFunc) (*SqsRoute, error) {
+	return NewSqsRecordRoute(eventSourceArn, func(ctx context.Context, record events.SQSMessage) error {
+		message, envelope := UnwrapSqsMessage(record)
+
+		return handler(ctx, message, envelope)
+	})
+}
+
+func isSnsEnvelope(body map[string]json.RawMessage) bool {
+	var envelopeType string
+
+	if err := json.Unmarshal(body[snsTypeField], &envelopeType); err != nil {
+		return false
+	}
+
+	_, hasTopicArn := body[snsTopicArnField]
+	_, hasMessage := body[snsMessageField]
+
+	return envelopeType == snsNotificationType && hasTopicArn && hasMessage
+}
+
+func isEventBridgeEnvelope(body map[string]json.RawMessage) bool {
+	for _, field := range []string{eventBridgeDetailField, eventBridgeDetailTypeField, eventBridgeSourceField} {
+		if _, ok := body[field]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mergeSnsMessageAttributes(
+	attributes map[string]events.SQSMessageAttribute,
+	snsAttributes map[string]interface{},
+) map[string]events.SQSMessageAttribute {
+	merged := map[string]events.SQSMessageAttribute{}
+
+	for name, snsAttribute := range snsAttributes {
+		snsAttributeVal, ok := snsAttribute.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		dataType, _ := snsAttributeVal[snsTypeField].(string)
+		value, _ := snsAttributeVal[snsValueField].(string)
+		attribute := events.SQSMessageAttribute{DataType: dataType}
+
+		if dataType == snsBinaryAttributeType {
+			attribute.BinaryValue, _ = base64.StdEncoding.DecodeString(value)
+		} else {
+			attribute.StringValue = &value
+		}
+
+		merged[name] = attribute
+	}
+
+	for name, attribute := range attributes {
+		merged[name] = attribute
+	}
+
+	return merged
+}
@@ -0,0 +1,154 @@
+package routes_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	snsEnvelopeBody = `{
+  "Type": "Notification",
+  "MessageId": "snsMessageId",
+  "TopicArn": "arn:aws:sns:us-east-2:123456789012:orders",
+  "Subject": "subject",
+  "Message": "{\"orderId\": \"1\"}",
+  "Timestamp": "2021-01-01T00:00:00.000Z",
+  "SignatureVersion": "1",
+  "Signature": "signature",
+  "SigningCertURL": "https://example.com/cert.pem",
+  "UnsubscribeURL": "https://example.com/unsubscribe",
+  "MessageAttributes": {
+    "type": {"Type": "String", "Value": "created"},
+    "payload": {"Type": "Binary", "Value": "YmluYXJ5"}
+  }
+}`
+	eventBridgeEnvelopeBody = `{
+  "version": "0",
+  "id": "eventId",
+  "detail-type": "Order Created",
+  "source": "shop",
+  "account": "123456789012",
+  "time": "2021-01-01T00:00:00Z",
+  "region": "us-east-2",
+  "resources": [],
+  "detail": {"orderId": "1"}
+}`
+)
+
+func Test_UnwrapSqsMessage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unwraps SNS notification", func(t *testing.T) {
+		message, envelope := routes.UnwrapSqsMessage(events.SQSMessage{
+			MessageId: "1",
+			Body:      snsEnvelopeBody,
+			MessageAttributes: map[string]events.SQSMessageAttribute{
+				"type": {DataType: "String", StringValue: stringPtr("sqs")},
+			},
+		})
+
+		assert.Equal(t, routes.SqsEnvelopeSns, envelope.Type)
+		require.NotNil(t, envelope.Sns)
+		assert.Equal(t, "arn:aws:sns:us-east-2:123456789012:orders", envelope.Sns.TopicArn)
+		assert.Equal(t, "snsMessageId", envelope.Sns.MessageID)
+		assert.Equal(t, "subject", envelope.Sns.Subject)
+		assert.Equal(t, "https://example.com/cert.pem", envelope.Sns.SigningCertURL)
+		assert.Nil(t, envelope.EventBridge)
+
+		assert.Equal(t, "1", message.MessageId)
+		assert.Equal(t, `{"orderId": "1"}`, message.Body)
+		assert.Equal(t, "sqs", *message.MessageAttributes["type"].StringValue)
+		assert.Equal(t, []byte("binary"), message.MessageAttributes["payload"].BinaryValue)
+		assert.Equal(t, "Binary", message.MessageAttributes["payload"].DataType)
+	})
+
+	t.Run("Unwraps EventBridge event", func(t *testing.T) {
+		message, envelope := routes.UnwrapSqsMessage(events.SQSMessage{Body: eventBridgeEnvelopeBody})
+
+		assert.Equal(t, routes.SqsEnvelopeEventBridge, envelope.Type)
+		require.NotNil(t, envelope.EventBridge)
+		assert.Equal(t, "Order Created", envelope.EventBridge.DetailType)
+		assert.Equal(t, "shop", envelope.EventBridge.Source)
+		assert.Nil(t, envelope.Sns)
+		assert.JSONEq(t, `{"orderId": "1"}`, message.Body)
+	})
+
+	t.Run("Returns messages without envelope as is", func(t *testing.T) {
+		for _, body := range []string{
+			`{"orderId": "1"}`,
+			`{"Type": "Notification", "Message": "no topic"}`,
+			`raw message`,
+		} {
+			record := events.SQSMessage{MessageId: "1", Body: body}
+			message, envelope := routes.UnwrapSqsMessage(record)
+
+			assert.Equal(t, record, message)
+			assert.Equal(t, routes.SqsEnvelope{Type: routes.SqsEnvelopeNone}, envelope)
+		}
+	})
+}
+
+func Test_SqsEnvelopeRoute(t *testing.T) {
+	t.Parallel()
+
+	sqsEvent := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(`{
+  "Records": [
+    {"messageId": "1", "eventSourceARN": "arn", "body": `+mustJsonString(t, snsEnvelopeBody)+`},
+    {"messageId": "2", "eventSourceARN": "arn", "body": `+mustJsonString(t, eventBridgeEnvelopeBody)+`},
+    {"messageId": "3", "eventSourceARN": "arn", "body": "{\"orderId\": \"3\"}"}
+  ]
+}`), &sqsEvent))
+
+	t.Run("Passes unwrapped messages and envelopes to the handler", func(t *testing.T) {
+		envelopes := map[string]string{}
+
+		route, err := routes.NewSqsEnvelopeRoute(".*", func(ctx context.Context, record events.SQSMessage, envelope routes.SqsEnvelope) error {
+			body := map[string]string{}
+			require.Nil(t, json.Unmarshal([]byte(record.Body), &body))
+			envelopes[record.MessageId] = envelope.Type + ":" + body["orderId"]
+
+			return nil
+		})
+
+		require.Nil(t, err)
+
+		resp, err := route.Handle(context.TODO(), sqsEvent)
+
+		assert.Nil(t, err)
+		assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}, resp)
+		assert.Equal(t, map[string]string{"1": "sns:1", "2": "eventbridge:1", "3": ":3"}, envelopes)
+	})
+
+	t.Run("WithEnvelopeUnwrapping passes unwrapped messages to any handler", func(t *testing.T) {
+		bodies := []string{}
+
+		route, err := routes.NewSqsRoute(".*", func(ctx context.Context, request events.SQSEvent) error {
+			for _, record := range request.Records {
+				bodies = append(bodies, record.Body)
+			}
+
+			return nil
+		})
+
+		require.Nil(t, err)
+
+		_, err = route.WithEnvelopeUnwrapping().Handle(context.TODO(), sqsEvent)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{`{"orderId": "1"}`, `{"orderId": "1"}`, `{"orderId": "3"}`}, bodies)
+	})
+}
+
+func mustJsonString(t *testing.T, value string) string {
+	encoded, err := json.Marshal(value)
+	require.Nil(t, err)
+
+	return string(encoded)
+}
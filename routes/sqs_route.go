@@ -6,11 +6,24 @@ import (
 	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"regexp"
+	"strings"
 )
 
+const (
+	sqsFifoSuffix = ".fifo"
+)
+
+// SqsRoute passes SQS events to the handler.
+// Routes created with NewSqsRecordRoute or NewSqsBatchRoute report batch item failures,
+// which requires ReportBatchItemFailures to be enabled on the event source mapping.
 type SqsRoute struct {
 	eventSourceArn *regexp.Regexp
 	handler        SqsHandlerFunc
+	batchHandler   SqsBatchHandlerFunc
+	filterCriteria *FilterCriteria
+	unwrap         bool
+	validator      DocumentValidator
+	invalidSink    SqsSinkFunc
 }
 
 func NewSqsRoute(eventSourceArn string, handler SqsHandlerFunc) (*SqsRoute, error) {
@@ -26,6 +39,57 @@ func NewSqsRoute(eventSourceArn string, handler SqsHandlerFunc) (*SqsRoute, erro
 	}, nil
 }
 
+// NewSqsBatchRoute creates a route which handler returns failed message IDs,
+// only failed messages are returned back to the queue.
+func NewSqsBatchRoute(eventSourceArn string, handler SqsBatchHandlerFunc) (*SqsRoute, error) {
+	compiledEventSourceArn, err := regexp.Compile(eventSourceArn)
+
+	if err != nil {
+		return nil, RouteCompileError.Wrap(err, "Invalid regexp given")
+	}
+
+	return &SqsRoute{
+		eventSourceArn: compiledEventSourceArn,
+		batchHandler:   handler,
+	}, nil
+}
+
+// NewSqsRecordRoute creates a route which calls the handler for every message,
+// messages for which the handler returns an error are reported as batch item failures.
+// For FIFO queues processing stops at the first failure and all remaining messages are reported as failed
+// to preserve the order.
+func NewSqsRecordRoute(eventSourceArn string, handler SqsRecordHandlerFunc) (*SqsRoute, error) {
+	return NewSqsBatchRoute(eventSourceArn, recordsBatchHandler(handler))
+}
+
+// WithFilterCriteria drops messages which do not match the criteria before calling the handler,
+// dropped messages are considered as processed. The handler is not called if all messages are dropped.
+func (route *SqsRoute) WithFilterCriteria(criteria *FilterCriteria) *SqsRoute {
+	route.filterCriteria = criteria
+
+	return route
+}
+
+// WithEnvelopeUnwrapping replaces bodies of the messages delivered by SNS or EventBridge with the inner messages
+// before calling the handler, see UnwrapSqsMessage. Filter criteria are applied to the original messages
+// the same way as by the event source mapping.
+func (route *SqsRoute) WithEnvelopeUnwrapping() *SqsRoute {
+	route.unwrap = true
+
+	return route
+}
+
+// WithValidation validates JSON bodies of the messages before calling the handler.
+// Invalid messages are passed to the sink with ValidationErrors and considered as processed if the sink succeeds.
+// Otherwise, or if the sink is nil, routes with batch item failures report them as failed
+// and other routes fail the whole batch.
+func (route *SqsRoute) WithValidation(validator DocumentValidator, sink SqsSinkFunc) *SqsRoute {
+	route.validator = validator
+	route.invalidSink = sink
+
+	return route
+}
+
 func (route *SqsRoute) Matches(event map[string]interface{}) bool {
 	if event["Records"] == nil {
 		return false
@@ -54,7 +118,7 @@ func (route *SqsRoute) Matches(event map[string]interface{}) bool {
 			return false
 		}
 
-		return true
+		break
 	}
 
 	return true
@@ -75,13 +139,145 @@ func (route *SqsRoute) Handle(ctx context.Context, event map[string]interface{})
 		return nil, RouteUnmarshalError.Wrap(err, "Failed to unmarshal request from the JSON")
 	}
 
+	if route.filterCriteria != nil {
+		request.Records = route.filterRecords(request.Records)
+
+		if len(request.Records) == 0 {
+			return route.emptyResponse(), nil
+		}
+	}
+
+	if route.unwrap {
+		for i, record := range request.Records {
+			request.Records[i], _ = UnwrapSqsMessage(record)
+		}
+	}
+
+	failed := []events.SQSMessage{}
+
+	if route.validator != nil {
+		request.Records, failed = route.validateRecords(ctx, request.Records)
+
+		if len(failed) > 0 && route.batchHandler == nil {
+			return nil, RouteValidationError.New("%d invalid messages were not sent to the sink", len(failed))
+		}
+
+		if len(request.Records) == 0 {
+			return sqsFailedResponse(route.emptyResponse(), failed), nil
+		}
+	}
+
+	if route.batchHandler != nil {
+		response, err := route.batchHandler(ctx, request)
+
+		if err != nil {
+			return response, err
+		}
+
+		return sqsFailedResponse(response, failed), nil
+	}
+
 	return nil, route.handler(ctx, request)
 }
 
-func (*SqsRoute) HasResponse() bool {
-	return false
+func (route *SqsRoute) HasResponse() bool {
+	return route.batchHandler != nil
 }
 
 func (route *SqsRoute) String() string {
 	return fmt.Sprintf("SQS event %s", route.eventSourceArn.String())
 }
+
+func (route *SqsRoute) filterRecords(records []events.SQSMessage) []events.SQSMessage {
+	filtered := []events.SQSMessage{}
+
+	for _, record := range records {
+		if route.filterCriteria.Matches(record) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	return filtered
+}
+
+func (route *SqsRoute) emptyResponse() interface{} {
+	if route.batchHandler != nil {
+		return events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+	}
+
+	return nil
+}
+
+// validateRecords returns valid records and invalid records which were not consumed by the sink.
+func (route *SqsRoute) validateRecords(
+	ctx context.Context,
+	records []events.SQSMessage,
+) ([]events.SQSMessage, []events.SQSMessage) {
+	valid := []events.SQSMessage{}
+	failed := []events.SQSMessage{}
+
+	for _, record := range records {
+		// messages after the first failure of a FIFO queue are failed too to keep the order of the message groups
+		if len(failed) > 0 && strings.HasSuffix(record.EventSourceARN, sqsFifoSuffix) {
+			failed = append(failed, record)
+
+			continue
+		}
+
+		errs := validateJsonBody(route.validator, record.Body, false)
+
+		if len(errs) == 0 {
+			valid = append(valid, record)
+
+			continue
+		}
+
+		if route.invalidSink == nil || route.invalidSink(ctx, record, errs) != nil {
+			failed = append(failed, record)
+		}
+	}
+
+	return valid, failed
+}
+
+func sqsFailedResponse(response interface{}, failed []events.SQSMessage) interface{} {
+	batchResponse, ok := response.(events.SQSEventResponse)
+
+	if !ok {
+		return response
+	}
+
+	for _, record := range failed {
+		batchResponse.BatchItemFailures = append(batchResponse.BatchItemFailures, events.SQSBatchItemFailure{
+			ItemIdentifier: record.MessageId,
+		})
+	}
+
+	return batchResponse
+}
+
+func recordsBatchHandler(handler SqsRecordHandlerFunc) SqsBatchHandlerFunc {
+	return func(ctx context.Context, request events.SQSEvent) (events.SQSEventResponse, error) {
+		response := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+		failed := false
+
+		for _, record := range request.Records {
+			if failed && strings.HasSuffix(record.EventSourceARN, sqsFifoSuffix) {
+				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+					ItemIdentifier: record.MessageId,
+				})
+
+				continue
+			}
+
+			if err := handler(ctx, record); err != nil {
+				failed = true
+				response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+					ItemIdentifier: record.MessageId,
+				})
+			}
+		}
+
+		return response, nil
+	}
+}
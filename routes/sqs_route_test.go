@@ -3,10 +3,14 @@ package routes_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/Napas/go-serverless-router/routes"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/joomcode/errorx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -17,12 +21,30 @@ func Test_SqsRoute(t *testing.T) {
 		return nil
 	}
 
+	nilBatchHandler := func(_ context.Context, _ events.SQSEvent) (events.SQSEventResponse, error) {
+		return events.SQSEventResponse{}, nil
+	}
+
+	nilRecordHandler := func(_ context.Context, _ events.SQSMessage) error {
+		return nil
+	}
+
 	t.Run("NewSqsRoute", func(t *testing.T) {
 		t.Run("Returns error on the invalid eventSourceArn regexp", func(t *testing.T) {
 			_, err := routes.NewSqsRoute("[[", nilHandler)
 
 			assert.Error(t, err)
 			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+
+			_, err = routes.NewSqsBatchRoute("[[", nilBatchHandler)
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+
+			_, err = routes.NewSqsRecordRoute("[[", nilRecordHandler)
+
+			assert.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
 		})
 	})
 
@@ -32,6 +54,14 @@ func Test_SqsRoute(t *testing.T) {
 
 			assert.False(t, sqsRoute.HasResponse())
 		})
+
+		t.Run("Returns true if route reports batch item failures", func(t *testing.T) {
+			batchRoute, _ := routes.NewSqsBatchRoute("/.*/", nilBatchHandler)
+			recordRoute, _ := routes.NewSqsRecordRoute("/.*/", nilRecordHandler)
+
+			assert.True(t, batchRoute.HasResponse())
+			assert.True(t, recordRoute.HasResponse())
+		})
 	})
 
 	t.Run("Matches", func(t *testing.T) {
@@ -62,7 +92,7 @@ func Test_SqsRoute(t *testing.T) {
 		}
 
 		for i, testCase := range testCases {
-			t.Run(string(i), func(t *testing.T) {
+			t.Run(strconv.Itoa(i), func(t *testing.T) {
 				sqsRoute, _ := routes.NewSqsRoute("^arn:aws:sqs:us-east-2:123456789012:my-queue$", nilHandler)
 				req := map[string]interface{}{}
 				json.Unmarshal([]byte(testCase.request), &req)
@@ -97,5 +127,241 @@ func Test_SqsRoute(t *testing.T) {
 
 			route.Handle(requestContext, event)
 		})
+
+		t.Run("Returns batch item failures from the batch handler", func(t *testing.T) {
+			response := events.SQSEventResponse{
+				BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "messageId"}},
+			}
+
+			route, err := routes.NewSqsBatchRoute(
+				".*",
+				func(ctx context.Context, request events.SQSEvent) (events.SQSEventResponse, error) {
+					assert.Equal(t, "messageId", request.Records[0].MessageId)
+
+					return response, nil
+				},
+			)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{
+				"Records": []map[string]interface{}{
+					{"messageId": "messageId", "eventSourceARN": "arn"},
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, response, resp)
+		})
+
+		t.Run("Reports messages failed in the record handler", func(t *testing.T) {
+			testCases := []struct {
+				description    string
+				eventSourceArn string
+				expected       []events.SQSBatchItemFailure
+			}{
+				{
+					description:    "Standard queue",
+					eventSourceArn: "arn:aws:sqs:us-east-2:123456789012:my-queue",
+					expected: []events.SQSBatchItemFailure{
+						{ItemIdentifier: "2"},
+					},
+				},
+				{
+					description:    "FIFO queue stops on the first failure",
+					eventSourceArn: "arn:aws:sqs:us-east-2:123456789012:my-queue.fifo",
+					expected: []events.SQSBatchItemFailure{
+						{ItemIdentifier: "2"},
+						{ItemIdentifier: "3"},
+					},
+				},
+			}
+
+			for _, testCase := range testCases {
+				t.Run(testCase.description, func(t *testing.T) {
+					handled := []string{}
+
+					route, err := routes.NewSqsRecordRoute(
+						".*",
+						func(ctx context.Context, record events.SQSMessage) error {
+							handled = append(handled, record.MessageId)
+
+							if record.MessageId == "2" {
+								return errors.New("error")
+							}
+
+							return nil
+						},
+					)
+
+					require.Nil(t, err)
+
+					records := []map[string]interface{}{}
+
+					for _, messageId := range []string{"1", "2", "3"} {
+						records = append(records, map[string]interface{}{
+							"messageId":      messageId,
+							"eventSourceARN": testCase.eventSourceArn,
+						})
+					}
+
+					resp, err := route.Handle(context.TODO(), map[string]interface{}{"Records": records})
+
+					assert.Nil(t, err)
+					assert.Equal(t, events.SQSEventResponse{BatchItemFailures: testCase.expected}, resp)
+
+					if strings.HasSuffix(testCase.eventSourceArn, ".fifo") {
+						assert.Equal(t, []string{"1", "2"}, handled)
+					} else {
+						assert.Equal(t, []string{"1", "2", "3"}, handled)
+					}
+				})
+			}
+		})
+
+		t.Run("Returns an empty batch item failures list if all messages succeeded", func(t *testing.T) {
+			route, err := routes.NewSqsRecordRoute(".*", nilRecordHandler)
+
+			require.Nil(t, err)
+
+			resp, err := route.Handle(context.TODO(), map[string]interface{}{
+				"Records": []map[string]interface{}{
+					{"messageId": "1", "eventSourceARN": "arn"},
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}, resp)
+		})
+	})
+
+	t.Run("Filters messages by the filter criteria", func(t *testing.T) {
+		criteria, err := routes.NewFilterCriteria(`{"body": {"type": ["order"]}}`)
+
+		require.Nil(t, err)
+
+		handled := []string{}
+		route, err := routes.NewSqsRecordRoute(".*", func(ctx context.Context, record events.SQSMessage) error {
+			handled = append(handled, record.MessageId)
+
+			return nil
+		})
+
+		require.Nil(t, err)
+
+		route = route.WithFilterCriteria(criteria)
+
+		filteredOut := map[string]interface{}{
+			"Records": []interface{}{
+				map[string]interface{}{"messageId": "1", "eventSourceARN": "arn", "body": `{"type": "refund"}`},
+			},
+		}
+
+		assert.True(t, route.Matches(filteredOut))
+
+		resp, err := route.Handle(context.TODO(), filteredOut)
+
+		assert.Nil(t, err)
+		assert.Empty(t, handled)
+		assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}, resp)
+
+		event := map[string]interface{}{
+			"Records": []interface{}{
+				map[string]interface{}{"messageId": "1", "eventSourceARN": "arn", "body": `{"type": "refund"}`},
+				map[string]interface{}{"messageId": "2", "eventSourceARN": "arn", "body": `{"type": "order"}`},
+			},
+		}
+
+		assert.True(t, route.Matches(event))
+
+		resp, err = route.Handle(context.TODO(), event)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2"}, handled)
+		assert.Equal(t, events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}, resp)
+	})
+
+	t.Run("Validates messages", func(t *testing.T) {
+		validator, err := routes.NewJsonSchemaValidator(`{"type": "object", "required": ["orderId"]}`)
+
+		require.Nil(t, err)
+
+		event := map[string]interface{}{
+			"Records": []interface{}{
+				map[string]interface{}{"messageId": "1", "eventSourceARN": "arn", "body": `{"orderId": "1"}`},
+				map[string]interface{}{"messageId": "2", "eventSourceARN": "arn", "body": `{}`},
+				map[string]interface{}{"messageId": "3", "eventSourceARN": "arn", "body": `not json`},
+			},
+		}
+
+		t.Run("Passes invalid messages to the sink", func(t *testing.T) {
+			handled := []string{}
+			sunk := map[string]string{}
+
+			route, err := routes.NewSqsRecordRoute(".*", func(ctx context.Context, record events.SQSMessage) error {
+				handled = append(handled, record.MessageId)
+
+				return nil
+			})
+
+			require.Nil(t, err)
+
+			route.WithValidation(validator, func(ctx context.Context, record events.SQSMessage, err error) error {
+				sunk[record.MessageId] = err.Error()
+
+				if record.MessageId == "3" {
+					return errors.New("error")
+				}
+
+				return nil
+			})
+
+			resp, err := route.Handle(context.TODO(), event)
+
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"1"}, handled)
+			assert.Equal(t, map[string]string{"2": "orderId is required", "3": "must be a valid JSON"}, sunk)
+			assert.Equal(t, events.SQSEventResponse{
+				BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "3"}},
+			}, resp)
+		})
+
+		t.Run("Fails the messages after the first invalid one of a FIFO queue", func(t *testing.T) {
+			fifoArn := "arn:aws:sqs:us-east-2:123456789012:orders.fifo"
+			handled := []string{}
+
+			route, err := routes.NewSqsRecordRoute(".*", func(ctx context.Context, record events.SQSMessage) error {
+				handled = append(handled, record.MessageId)
+
+				return nil
+			})
+
+			require.Nil(t, err)
+
+			resp, err := route.WithValidation(validator, nil).Handle(context.TODO(), map[string]interface{}{
+				"Records": []interface{}{
+					map[string]interface{}{"messageId": "1", "eventSourceARN": fifoArn, "body": `{"orderId": "1"}`},
+					map[string]interface{}{"messageId": "2", "eventSourceARN": fifoArn, "body": `{}`},
+					map[string]interface{}{"messageId": "3", "eventSourceARN": fifoArn, "body": `{"orderId": "3"}`},
+				},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"1"}, handled)
+			assert.Equal(t, events.SQSEventResponse{
+				BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "2"}, {ItemIdentifier: "3"}},
+			}, resp)
+		})
+
+		t.Run("Fails the batch of the route without batch item failures", func(t *testing.T) {
+			route, err := routes.NewSqsRoute(".*", nilHandler)
+
+			require.Nil(t, err)
+
+			_, err = route.WithValidation(validator, nil).Handle(context.TODO(), event)
+
+			require.Error(t, err)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteValidationError))
+		})
 	})
 }
@@ -0,0 +1,318 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	validateTag    = "validate"
+	patternTag     = "pattern"
+	jsonTag        = "json"
+	ruleRequired   = "required"
+	ruleMin        = "min"
+	ruleMax        = "max"
+	ruleOneOf      = "oneof"
+	ruleSeparator  = "="
+	tagSeparator   = ","
+	oneOfSeparator = " "
+)
+
+// structValidator validates documents against the rules from the struct tags.
+type structValidator struct {
+	fields []*structFieldRules
+}
+
+type structFieldRules struct {
+	name     string
+	jsonType string
+	required bool
+	min      *float64
+	max      *float64
+	oneOf    []string
+	pattern  *regexp.Regexp
+	object   *structValidator
+	items    *structFieldRules
+}
+
+// NewStructValidator creates a validator from the struct tags of the prototype, e.g.
+//
+//	type ListUsersQuery struct {
+//		Limit int    `json:"limit" validate:"min=1,max=100"`
+//		Sort  string `json:"sort" validate:"required,oneof=name age"`
+//		Name  string `json:"name" pattern:"^[a-z]+$"`
+//	}
+//
+// Fields are named by the json tag and their types are checked by the Go types.
+// min and max limit numbers, lengths of strings and sizes of slices and maps.
+// required also rejects empty strings, other rules are applied to the empty strings of the optional fields.
+func NewStructValidator(prototype interface{}) (DocumentValidator, error) {
+	structType := reflect.TypeOf(prototype)
+
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, RouteCompileError.New("Expected a struct, got %T", prototype)
+	}
+
+	return compileStructValidator(structType, map[reflect.Type]*structValidator{})
+}
+
+func (validator *structValidator) ValidateDocument(document interface{}) ValidationErrors {
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+
+	return validator.validate(document, "")
+}
+
+func (validator *structValidator) ValidateParameters(parameters map[string]string) ValidationErrors {
+	return validator.ValidateDocument(coerceParameters(parameters, func(name string) string {
+		for _, field := range validator.fields {
+			if field.name == name {
+				return field.jsonType
+			}
+		}
+
+		return jsonTypeString
+	}))
+}
+
+func (validator *structValidator) validate(document interface{}, path string) ValidationErrors {
+	object, ok := document.(map[string]interface{})
+
+	if !ok {
+		return ValidationErrors{{Field: path, Message: "must be object"}}
+	}
+
+	errs := ValidationErrors{}
+
+	for _, field := range validator.fields {
+		value, exists := object[field.name]
+		fieldPath := validationPath(path, field.name)
+
+		// empty strings are missing only for the required fields, other rules still apply to them
+		if !exists || value == nil || (field.required && value == "") {
+			if field.required {
+				errs = append(errs, FieldError{Field: fieldPath, Message: "is required"})
+			}
+
+			continue
+		}
+
+		errs = append(errs, field.validate(value, fieldPath)...)
+	}
+
+	return errs
+}
+
+func (field *structFieldRules) validate(value interface{}, path string) ValidationErrors {
+	if field.jsonType != "" && !hasJsonType(value, field.jsonType) {
+		return ValidationErrors{{Field: path, Message: "must be " + field.jsonType}}
+	}
+
+	errs := ValidationErrors{}
+
+	if size, ok := validationSize(value); ok {
+		if field.min != nil && size < *field.min {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at least %v", *field.min)})
+		}
+
+		if field.max != nil && size > *field.max {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("must be at most %v", *field.max)})
+		}
+	}
+
+	if len(field.oneOf) > 0 && !hasString(field.oneOf, fmt.Sprint(value)) {
+		errs = append(errs, FieldError{Field: path, Message: "must be one of " + strings.Join(field.oneOf, ", ")})
+	}
+
+	if text, ok := value.(string); ok && field.pattern != nil && !field.pattern.MatchString(text) {
+		errs = append(errs, FieldError{Field: path, Message: "must match " + field.pattern.String()})
+	}
+
+	if field.object != nil {
+		errs = append(errs, field.object.validate(value, path)...)
+	}
+
+	if items, ok := value.([]interface{}); ok && field.items != nil {
+		for i, item := range items {
+			errs = append(errs, field.items.validate(item, validationIndexPath(path, i))...)
+		}
+	}
+
+	return errs
+}
+
+// compileStructValidator reuses validators of the already compiled types, so recursive types can be validated.
+func compileStructValidator(
+	structType reflect.Type,
+	compiled map[reflect.Type]*structValidator,
+) (*structValidator, error) {
+	if validator, ok := compiled[structType]; ok {
+		return validator, nil
+	}
+
+	validator := &structValidator{fields: []*structFieldRules{}}
+	compiled[structType] = validator
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		jsonName := strings.Split(field.Tag.Get(jsonTag), tagSeparator)[0]
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && jsonName == "" {
+			embedded, err := compileStructValidator(field.Type, map[reflect.Type]*structValidator{})
+
+			if err != nil {
+				return nil, err
+			}
+
+			validator.fields = append(validator.fields, embedded.fields...)
+
+			continue
+		}
+
+		if field.PkgPath != "" || jsonName == "-" {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		rules, err := compileFieldRules(field.Type, field.Tag, compiled)
+
+		if err != nil {
+			return nil, RouteCompileError.Wrap(err, "Invalid rules of the field %s", field.Name)
+		}
+
+		rules.name = jsonName
+		validator.fields = append(validator.fields, rules)
+	}
+
+	return validator, nil
+}
+
+func compileFieldRules(
+	fieldType reflect.Type,
+	tag reflect.StructTag,
+	compiled map[reflect.Type]*structValidator,
+) (*structFieldRules, error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	rules := &structFieldRules{jsonType: jsonTypeOfKind(fieldType)}
+
+	switch {
+	case fieldType == timeType:
+		rules.jsonType = jsonTypeString
+	case fieldType.Kind() == reflect.Struct:
+		object, err := compileStructValidator(fieldType, compiled)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules.object = object
+	case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8,
+		fieldType.Kind() == reflect.Array:
+		items, err := compileFieldRules(fieldType.Elem(), "", compiled)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules.items = items
+	}
+
+	if pattern := tag.Get(patternTag); pattern != "" {
+		compiled, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, RouteCompileError.Wrap(err, "Invalid pattern given")
+		}
+
+		rules.pattern = compiled
+	}
+
+	for _, rule := range strings.Split(tag.Get(validateTag), tagSeparator) {
+		name, argument := rule, ""
+
+		if i := strings.Index(rule, ruleSeparator); i >= 0 {
+			name, argument = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "":
+		case ruleRequired:
+			rules.required = true
+		case ruleMin, ruleMax:
+			limit, err := strconv.ParseFloat(argument, 64)
+
+			if err != nil {
+				return nil, RouteCompileError.Wrap(err, "Invalid %s given", name)
+			}
+
+			if name == ruleMin {
+				rules.min = &limit
+			} else {
+				rules.max = &limit
+			}
+		case ruleOneOf:
+			rules.oneOf = strings.Split(argument, oneOfSeparator)
+		default:
+			return nil, RouteCompileError.New("Unknown rule %s", name)
+		}
+	}
+
+	return rules, nil
+}
+
+func jsonTypeOfKind(fieldType reflect.Type) string {
+	switch fieldType.Kind() {
+	case reflect.String:
+		return jsonTypeString
+	case reflect.Bool:
+		return jsonTypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonTypeInteger
+	case reflect.Float32, reflect.Float64:
+		return jsonTypeNumber
+	case reflect.Struct, reflect.Map:
+		return jsonTypeObject
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return jsonTypeString
+		}
+
+		return jsonTypeArray
+	case reflect.Array:
+		return jsonTypeArray
+	}
+
+	return ""
+}
+
+// validationSize returns the value compared with min and max: numbers as is, lengths of strings and sizes of lists.
+func validationSize(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case string:
+		return float64(utf8.RuneCountInString(value)), true
+	case []interface{}:
+		return float64(len(value)), true
+	case map[string]interface{}:
+		return float64(len(value)), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,161 @@
+package routes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatedCategory struct {
+	Name     string              `json:"name" validate:"required"`
+	Children []validatedCategory `json:"children"`
+}
+
+type validatedUser struct {
+	Name      string             `json:"name" validate:"required,min=2,max=5" pattern:"^[a-z]+$"`
+	Age       int                `json:"age" validate:"min=18"`
+	Score     float64            `json:"score"`
+	Role      string             `json:"role" validate:"oneof=admin user"`
+	Active    *bool              `json:"active"`
+	Tags      []string           `json:"tags" validate:"max=2"`
+	Address   validatedAddress   `json:"address"`
+	Addresses []validatedAddress `json:"addresses"`
+	Category  *validatedCategory `json:"category"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Ignored   string             `json:"-" validate:"required"`
+}
+
+func Test_StructValidator(t *testing.T) {
+	t.Parallel()
+
+	validator, err := routes.NewStructValidator(&validatedUser{})
+	require.Nil(t, err)
+
+	t.Run("NewStructValidator returns an error", func(t *testing.T) {
+		for _, prototype := range []interface{}{
+			"not a struct",
+			nil,
+			struct {
+				Field string `validate:"unknown"`
+			}{},
+			struct {
+				Field string `validate:"min=a"`
+			}{},
+			struct {
+				Field string `pattern:"[["`
+			}{},
+		} {
+			_, err := routes.NewStructValidator(prototype)
+
+			require.Error(t, err, "%v", prototype)
+			assert.True(t, err.(*errorx.Error).IsOfType(routes.RouteCompileError))
+		}
+	})
+
+	t.Run("ValidateDocument", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			document    interface{}
+			expected    routes.ValidationErrors
+		}{
+			{
+				description: "Valid document",
+				document: map[string]interface{}{
+					"name":      "john",
+					"age":       float64(18),
+					"score":     1.5,
+					"role":      "admin",
+					"active":    true,
+					"tags":      []interface{}{"a"},
+					"address":   map[string]interface{}{"city": "Vilnius"},
+					"category":  map[string]interface{}{"name": "a", "children": []interface{}{map[string]interface{}{"name": "b"}}},
+					"createdAt": "2021-01-01T00:00:00Z",
+				},
+				expected: routes.ValidationErrors{},
+			},
+			{
+				description: "Missing required fields",
+				document:    nil,
+				expected:    routes.ValidationErrors{{Field: "name", Message: "is required"}},
+			},
+			{
+				description: "Empty strings",
+				document:    map[string]interface{}{"name": "", "role": ""},
+				expected: routes.ValidationErrors{
+					{Field: "name", Message: "is required"},
+					{Field: "role", Message: "must be one of admin, user"},
+				},
+			},
+			{
+				description: "Invalid types",
+				document: map[string]interface{}{
+					"name":    float64(1),
+					"age":     1.5,
+					"active":  "true",
+					"tags":    "a",
+					"address": "Vilnius",
+				},
+				expected: routes.ValidationErrors{
+					{Field: "name", Message: "must be string"},
+					{Field: "age", Message: "must be integer"},
+					{Field: "active", Message: "must be boolean"},
+					{Field: "tags", Message: "must be array"},
+					{Field: "address", Message: "must be object"},
+				},
+			},
+			{
+				description: "Invalid values",
+				document: map[string]interface{}{
+					"name":      "JOHNNY",
+					"age":       float64(17),
+					"role":      "guest",
+					"tags":      []interface{}{"a", "b", float64(1)},
+					"address":   map[string]interface{}{},
+					"addresses": []interface{}{map[string]interface{}{"city": "Vilnius"}, map[string]interface{}{}},
+					"category":  map[string]interface{}{"name": "a", "children": []interface{}{map[string]interface{}{}}},
+				},
+				expected: routes.ValidationErrors{
+					{Field: "name", Message: "must be at most 5"},
+					{Field: "name", Message: "must match ^[a-z]+$"},
+					{Field: "age", Message: "must be at least 18"},
+					{Field: "role", Message: "must be one of admin, user"},
+					{Field: "tags", Message: "must be at most 2"},
+					{Field: "tags[2]", Message: "must be string"},
+					{Field: "address.city", Message: "is required"},
+					{Field: "addresses[1].city", Message: "is required"},
+					{Field: "category.children[0].name", Message: "is required"},
+				},
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				assert.Equal(t, testCase.expected, validator.ValidateDocument(testCase.document))
+			})
+		}
+	})
+
+	t.Run("ValidateParameters converts parameters to the field types", func(t *testing.T) {
+		assert.Equal(t, routes.ValidationErrors{}, validator.ValidateParameters(map[string]string{
+			"name":   "john",
+			"age":    "20",
+			"active": "true",
+		}))
+		assert.Equal(t, routes.ValidationErrors{
+			{Field: "age", Message: "must be integer"},
+			{Field: "active", Message: "must be boolean"},
+		}, validator.ValidateParameters(map[string]string{
+			"name":   "john",
+			"age":    "twenty",
+			"active": "yes",
+		}))
+	})
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// NewTypedSqsRoute creates a route which decodes the JSON body of every message into Msg,
+// see NewSqsRecordRoute. Messages which can not be decoded or fail validation, see Validator,
+// are reported as batch item failures without calling the handler.
+func NewTypedSqsRoute[Msg any](eventSourceArn string, handler TypedSqsHandlerFunc[Msg]) (*SqsRoute, error) {
+	return NewSqsRecordRoute(eventSourceArn, func(ctx context.Context, record events.SQSMessage) error {
+		message, err := decodeJsonBody[Msg](record.Body, false)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(ctx, message, record)
+	})
+}
@@ -0,0 +1,44 @@
+package routes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Napas/go-serverless-router/routes"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TypedSqsRoute(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Decodes messages and reports the ones which can not be decoded", func(t *testing.T) {
+		handled := []createUserRequest{}
+
+		route, err := routes.NewTypedSqsRoute(
+			".*",
+			func(ctx context.Context, message createUserRequest, record events.SQSMessage) error {
+				handled = append(handled, message)
+
+				return nil
+			},
+		)
+
+		require.Nil(t, err)
+
+		resp, err := route.Handle(context.TODO(), map[string]interface{}{
+			"Records": []interface{}{
+				map[string]interface{}{"messageId": "1", "eventSourceARN": "arn", "body": `{"name": "john"}`},
+				map[string]interface{}{"messageId": "2", "eventSourceARN": "arn", "body": `not json`},
+				map[string]interface{}{"messageId": "3", "eventSourceARN": "arn", "body": `{"name": ""}`},
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []createUserRequest{{Name: "john"}}, handled)
+		assert.Equal(t, events.SQSEventResponse{
+			BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "2"}, {ItemIdentifier: "3"}},
+		}, resp)
+	})
+}
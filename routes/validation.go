@@ -0,0 +1,212 @@
+package routes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joomcode/errorx"
+)
+
+const (
+	ValidationInPath   = "path"
+	ValidationInQuery  = "query"
+	ValidationInHeader = "header"
+	ValidationInBody   = "body"
+
+	jsonTypeString  = "string"
+	jsonTypeInteger = "integer"
+	jsonTypeNumber  = "number"
+	jsonTypeBoolean = "boolean"
+	jsonTypeObject  = "object"
+	jsonTypeArray   = "array"
+	jsonTypeNull    = "null"
+)
+
+// FieldError describes a single invalid field, nested fields are written as address.city and items[0].
+type FieldError struct {
+	In      string `json:"in,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects all invalid fields of the request or the message.
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	messages := []string{}
+
+	for _, err := range errs {
+		field := err.Field
+
+		if err.In != "" {
+			field = err.In + " " + field
+		}
+
+		messages = append(messages, strings.TrimSpace(field+" "+err.Message))
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// DocumentValidator validates decoded JSON documents, see NewStructValidator and NewJsonSchemaValidator.
+type DocumentValidator interface {
+	ValidateDocument(document interface{}) ValidationErrors
+	// ValidateParameters validates path parameters, query strings or headers,
+	// values are converted to the expected types before the validation.
+	ValidateParameters(parameters map[string]string) ValidationErrors
+}
+
+// RequestValidation holds validators of the API Gateway request parts, nil validators are skipped.
+// Header names are lower cased before the validation.
+type RequestValidation struct {
+	PathParameters DocumentValidator
+	QueryStrings   DocumentValidator
+	Headers        DocumentValidator
+	Body           DocumentValidator
+}
+
+// AsValidationErrors finds ValidationErrors in the error or its causes.
+func AsValidationErrors(err error) (ValidationErrors, bool) {
+	for err != nil {
+		if validationErrors, ok := err.(ValidationErrors); ok {
+			return validationErrors, true
+		}
+
+		errorxErr, ok := err.(*errorx.Error)
+
+		if !ok {
+			return nil, false
+		}
+
+		err = errorxErr.Cause()
+	}
+
+	return nil, false
+}
+
+func (validation RequestValidation) validate(
+	pathParameters map[string]string,
+	queryStrings map[string]string,
+	headers map[string]string,
+	body string,
+	isBase64Encoded bool,
+) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if validation.PathParameters != nil {
+		errs = append(errs, withLocation(ValidationInPath, validation.PathParameters.ValidateParameters(pathParameters))...)
+	}
+
+	if validation.QueryStrings != nil {
+		errs = append(errs, withLocation(ValidationInQuery, validation.QueryStrings.ValidateParameters(queryStrings))...)
+	}
+
+	if validation.Headers != nil {
+		lowerCasedHeaders := map[string]string{}
+
+		for name, value := range headers {
+			lowerCasedHeaders[strings.ToLower(name)] = value
+		}
+
+		errs = append(errs, withLocation(ValidationInHeader, validation.Headers.ValidateParameters(lowerCasedHeaders))...)
+	}
+
+	if validation.Body != nil {
+		errs = append(errs, withLocation(ValidationInBody, validateJsonBody(validation.Body, body, isBase64Encoded))...)
+	}
+
+	return errs
+}
+
+func validateJsonBody(validator DocumentValidator, body string, isBase64Encoded bool) ValidationErrors {
+	if isBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+
+		if err != nil {
+			return ValidationErrors{{Message: "must be base64 encoded"}}
+		}
+
+		body = string(decoded)
+	}
+
+	var document interface{}
+
+	if body != "" {
+		if err := json.Unmarshal([]byte(body), &document); err != nil {
+			return ValidationErrors{{Message: "must be a valid JSON"}}
+		}
+	}
+
+	return validator.ValidateDocument(document)
+}
+
+func withLocation(in string, errs ValidationErrors) ValidationErrors {
+	for i := range errs {
+		errs[i].In = in
+	}
+
+	return errs
+}
+
+// coerceParameters converts parameters to the JSON types returned by typeOf,
+// values which can not be converted are left as strings to fail the type check.
+func coerceParameters(parameters map[string]string, typeOf func(name string) string) map[string]interface{} {
+	document := map[string]interface{}{}
+
+	for name, value := range parameters {
+		document[name] = value
+
+		switch typeOf(name) {
+		case jsonTypeInteger, jsonTypeNumber:
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				document[name] = number
+			}
+		case jsonTypeBoolean:
+			if boolean, err := strconv.ParseBool(value); err == nil {
+				document[name] = boolean
+			}
+		}
+	}
+
+	return document
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return jsonTypeNull
+	case string:
+		return jsonTypeString
+	case bool:
+		return jsonTypeBoolean
+	case float64:
+		if value == float64(int64(value)) {
+			return jsonTypeInteger
+		}
+
+		return jsonTypeNumber
+	case map[string]interface{}:
+		return jsonTypeObject
+	case []interface{}:
+		return jsonTypeArray
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func hasJsonType(value interface{}, expected string) bool {
+	actual := jsonTypeOf(value)
+
+	return actual == expected || (expected == jsonTypeNumber && actual == jsonTypeInteger)
+}
+
+func validationPath(path string, name string) string {
+	return dynamoDbPath(path, name)
+}
+
+func validationIndexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}